@@ -0,0 +1,213 @@
+package configuration
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+}
+
+func (c *Configuration) GetCanonical(key string) string {
+	return strings.ToLower(strings.TrimSpace(c.Get(key)))
+}
+
+func (c *Configuration) GetWeekdays(key string) ([]time.Weekday, error) {
+	results := make([]time.Weekday, 0)
+	value := c.Get(key)
+	if len(strings.TrimSpace(value)) == 0 {
+		return results, nil
+	}
+	for _, token := range strings.Split(value, ",") {
+		if day, found := weekdays[strings.ToLower(strings.TrimSpace(token))]; !found {
+			return nil, fmt.Errorf("key '%s': unrecognized weekday '%s'", key, token)
+		} else {
+			results = append(results, day)
+		}
+	}
+	return results, nil
+}
+
+func (c *Configuration) GetSliceUnion(sep string, keys ...string) []string {
+	results := make([]string, 0)
+	seen := make(map[string]struct{})
+	for _, value := range c.GetSlice(keys) {
+		for _, element := range splitList(value, sep) {
+			if _, found := seen[element]; !found {
+				seen[element] = struct{}{}
+				results = append(results, element)
+			}
+		}
+	}
+	return results
+}
+
+func (c *Configuration) GetCount(key, sep string) int {
+	return len(splitList(c.Get(key), sep))
+}
+
+func (c *Configuration) GetSet(key, sep string) map[string]struct{} {
+	results := make(map[string]struct{})
+	for _, element := range splitList(c.Get(key), sep) {
+		results[element] = struct{}{}
+	}
+	return results
+}
+
+func splitList(value, sep string) []string {
+	results := make([]string, 0)
+	if len(value) == 0 {
+		return results
+	}
+	for _, element := range strings.Split(value, sep) {
+		if element = strings.TrimSpace(element); len(element) != 0 {
+			results = append(results, element)
+		}
+	}
+	return results
+}
+
+func (c *Configuration) GetDurationDefaultUnit(key string, unit time.Duration) (time.Duration, error) {
+	value, err := c.lookup(key)
+	if err != nil {
+		return 0, err
+	}
+	value = strings.TrimSpace(value)
+	if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if c.ShouldLogUpdates.Load() && c.firstHint(key, value) {
+			c.logf("Configuration::GetDurationDefaultUnit key '%s' has bare number '%s', interpreting as %v; add a unit suffix\n", escape(key), escape(value), time.Duration(n)*unit)
+		}
+		return time.Duration(n) * unit, nil
+	} else if d, err := time.ParseDuration(value); err != nil {
+		return 0, fmt.Errorf("key '%s': %w", key, err)
+	} else {
+		return d, nil
+	}
+}
+
+// firstHint reports whether the bare-number hint for key and value has not
+// been logged yet, so that a value read on every request is logged once.
+func (c *Configuration) firstHint(key, value string) bool {
+	c.computeMutex.Lock()
+	defer c.computeMutex.Unlock()
+	if _, found := c.hinted[[2]string{key, value}]; found {
+		return false
+	} else if c.hinted == nil {
+		c.hinted = make(map[[2]string]struct{})
+	}
+	c.hinted[[2]string{key, value}] = struct{}{}
+	return true
+}
+
+func (c *Configuration) GetIntClamped(key string, min, max, def int) int {
+	if value, err := c.lookup(key); err != nil {
+		return def
+	} else if n, err := strconv.Atoi(strings.TrimSpace(value)); err != nil {
+		return def
+	} else {
+		return clamp(c, "GetIntClamped", key, n, min, max)
+	}
+}
+
+func (c *Configuration) GetFloatClamped(key string, min, max, def float64) float64 {
+	if value, err := c.lookup(key); err != nil {
+		return def
+	} else if f, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err != nil || math.IsNaN(f) {
+		return def
+	} else {
+		return clamp(c, "GetFloatClamped", key, f, min, max)
+	}
+}
+
+func clamp[T int | float64](c *Configuration, caller, key string, value, min, max T) T {
+	if value < min {
+		c.logf("Configuration::%s clamping key '%s' value %v to minimum %v\n", caller, escape(key), value, min)
+		return min
+	} else if value > max {
+		c.logf("Configuration::%s clamping key '%s' value %v to maximum %v\n", caller, escape(key), value, max)
+		return max
+	}
+	return value
+}
+
+func (c *Configuration) GetGlob(key string) ([]string, error) {
+	pattern := strings.TrimSpace(c.Get(key))
+	if len(pattern) == 0 {
+		return []string{}, nil
+	} else if matches, err := filepath.Glob(pattern); err != nil {
+		return nil, fmt.Errorf("key '%s': %w", key, err)
+	} else if matches == nil {
+		return []string{}, nil
+	} else {
+		return matches, nil
+	}
+}
+
+func (c *Configuration) GetQuotedSlice(key, sep string) ([]string, error) {
+	results := make([]string, 0)
+	value := c.Get(key)
+	if len(value) == 0 || len(sep) == 0 {
+		return results, nil
+	}
+	elements, err := splitQuoted(value, sep)
+	if err != nil {
+		return nil, fmt.Errorf("key '%s': %w", key, err)
+	}
+	for _, element := range elements {
+		if element = strings.TrimSpace(element); len(element) == 0 {
+			continue
+		} else if quote := element[0]; quote != '"' && quote != '\'' {
+			results = append(results, element)
+		} else if len(element) < 2 || element[len(element)-1] != quote {
+			return nil, fmt.Errorf("key '%s': malformed quoted element %s", key, element)
+		} else {
+			results = append(results, unescapeQuoted(element[1:len(element)-1]))
+		}
+	}
+	return results, nil
+}
+
+func splitQuoted(value, sep string) ([]string, error) {
+	results := make([]string, 0)
+	start, quote := 0, byte(0)
+	for i := 0; i < len(value); i++ {
+		switch {
+		case quote != 0 && value[i] == '\\':
+			i++
+		case quote != 0 && value[i] == quote:
+			quote = 0
+		case quote == 0 && (value[i] == '"' || value[i] == '\''):
+			quote = value[i]
+		case quote == 0 && strings.HasPrefix(value[i:], sep):
+			results = append(results, value[start:i])
+			start = i + len(sep)
+			i = start - 1
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote in %s", value[start:])
+	}
+	return append(results, value[start:]), nil
+}
+
+func unescapeQuoted(s string) string {
+	var builder strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+		builder.WriteByte(s[i])
+	}
+	return builder.String()
+}
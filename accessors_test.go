@@ -0,0 +1,185 @@
+package configuration
+
+import (
+	"errors"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetCanonical(t *testing.T) {
+	config, _ := newTestConfiguration(t, "")
+	config.SetKeyValue("driver", "  PostGreSQL \t")
+	if got := config.GetCanonical("driver"); got != "postgresql" {
+		t.Errorf("GetCanonical = %q, want postgresql", got)
+	}
+	if got := config.GetCanonical("missing"); got != "" {
+		t.Errorf("GetCanonical of a missing key = %q, want empty", got)
+	}
+}
+
+func TestGetWeekdays(t *testing.T) {
+	config, _ := newTestConfiguration(t, "short=mon,wed,fri\nfull=Monday, Saturday\nmixed=sUN,TuEsDaY\nbad=mon,funday\n")
+	tests := []struct {
+		key  string
+		want []time.Weekday
+	}{
+		{"short", []time.Weekday{time.Monday, time.Wednesday, time.Friday}},
+		{"full", []time.Weekday{time.Monday, time.Saturday}},
+		{"mixed", []time.Weekday{time.Sunday, time.Tuesday}},
+		{"missing", []time.Weekday{}},
+	}
+	for _, test := range tests {
+		if got, err := config.GetWeekdays(test.key); err != nil {
+			t.Errorf("GetWeekdays(%q): %v", test.key, err)
+		} else if !slices.Equal(got, test.want) {
+			t.Errorf("GetWeekdays(%q) = %v, want %v", test.key, got, test.want)
+		}
+	}
+	if _, err := config.GetWeekdays("bad"); err == nil {
+		t.Error("GetWeekdays accepted an unrecognized day")
+	}
+}
+
+func TestGetSliceUnion(t *testing.T) {
+	config, _ := newTestConfiguration(t, "base_hosts=a, b,c\nextra_hosts=c,d ,a\ntemp_hosts=e,b\n")
+	got := config.GetSliceUnion(",", "base_hosts", "extra_hosts", "missing", "temp_hosts")
+	if want := []string{"a", "b", "c", "d", "e"}; !slices.Equal(got, want) {
+		t.Errorf("GetSliceUnion = %v, want %v", got, want)
+	}
+}
+
+func TestGetDurationDefaultUnit(t *testing.T) {
+	config, _ := newTestConfiguration(t, "bare=30\nseconds=30s\nmillis=500ms\n")
+	tests := []struct {
+		key  string
+		want time.Duration
+	}{
+		{"bare", 30 * time.Second},
+		{"seconds", 30 * time.Second},
+		{"millis", 500 * time.Millisecond},
+	}
+	for _, test := range tests {
+		if got, err := config.GetDurationDefaultUnit(test.key, time.Second); err != nil {
+			t.Errorf("GetDurationDefaultUnit(%q): %v", test.key, err)
+		} else if got != test.want {
+			t.Errorf("GetDurationDefaultUnit(%q) = %v, want %v", test.key, got, test.want)
+		}
+	}
+}
+
+func TestGetCount(t *testing.T) {
+	config, _ := newTestConfiguration(t, "retries=a, b ,,a,c, ,\n")
+	if got := config.GetCount("retries", ","); got != 4 {
+		t.Errorf("GetCount = %d, want 4", got)
+	} else if got := config.GetCount("missing", ","); got != 0 {
+		t.Errorf("GetCount of a missing key = %d, want 0", got)
+	}
+}
+
+func TestGetClamped(t *testing.T) {
+	config, _ := newTestConfiguration(t, "low=-5\nhigh=500\nok=50\nbad=lots\n")
+	tests := []struct {
+		key       string
+		wantInt   int
+		wantFloat float64
+	}{
+		{"low", 1, 1},
+		{"high", 100, 100},
+		{"ok", 50, 50},
+		{"missing", 10, 10},
+		{"bad", 10, 10},
+	}
+	for _, test := range tests {
+		if got := config.GetIntClamped(test.key, 1, 100, 10); got != test.wantInt {
+			t.Errorf("GetIntClamped(%q) = %d, want %d", test.key, got, test.wantInt)
+		}
+		if got := config.GetFloatClamped(test.key, 1, 100, 10); got != test.wantFloat {
+			t.Errorf("GetFloatClamped(%q) = %v, want %v", test.key, got, test.wantFloat)
+		}
+	}
+}
+
+func TestGetGlob(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.so", "b.so", "c.txt"} {
+		writeFile(t, filepath.Join(dir, name), "")
+	}
+	config, _ := newTestConfiguration(t, "")
+	config.SetKeyValue("plugins", filepath.Join(dir, "*.so"))
+	if got, err := config.GetGlob("plugins"); err != nil {
+		t.Errorf("GetGlob: %v", err)
+	} else if want := []string{filepath.Join(dir, "a.so"), filepath.Join(dir, "b.so")}; !slices.Equal(got, want) {
+		t.Errorf("GetGlob = %v, want %v", got, want)
+	}
+	config.SetKeyValue("malformed", filepath.Join(dir, "[.so"))
+	if _, err := config.GetGlob("malformed"); !errors.Is(err, filepath.ErrBadPattern) {
+		t.Errorf("GetGlob of a malformed pattern = %v, want %v", err, filepath.ErrBadPattern)
+	}
+	if got, err := config.GetGlob("missing"); err != nil || len(got) != 0 {
+		t.Errorf("GetGlob of a missing key = %v, %v, want none", got, err)
+	}
+}
+
+func TestGetQuotedSlice(t *testing.T) {
+	config, _ := newTestConfiguration(t, "")
+	config.SetKeyValue("names", `plain, "a, b", 'say \'hi\'', "x\"y"`)
+	config.SetKeyValue("unterminated", `ok, "open, rest`)
+	if got, err := config.GetQuotedSlice("names", ","); err != nil {
+		t.Errorf("GetQuotedSlice: %v", err)
+	} else if want := []string{"plain", "a, b", "say 'hi'", `x"y`}; !slices.Equal(got, want) {
+		t.Errorf("GetQuotedSlice = %q, want %q", got, want)
+	}
+	if _, err := config.GetQuotedSlice("unterminated", ","); err == nil {
+		t.Error("GetQuotedSlice accepted an unterminated quote")
+	}
+	if got, err := config.GetQuotedSlice("missing", ","); err != nil || len(got) != 0 {
+		t.Errorf("GetQuotedSlice of a missing key = %v, %v, want none", got, err)
+	}
+}
+
+func TestGetSet(t *testing.T) {
+	config, _ := newTestConfiguration(t, "allow=alice, bob,,carol \n")
+	set := config.GetSet("allow", ",")
+	for _, member := range []string{"alice", "bob", "carol"} {
+		if _, found := set[member]; !found {
+			t.Errorf("%s missing from %v", member, set)
+		}
+	}
+	if _, found := set["mallory"]; found || len(set) != 3 {
+		t.Errorf("GetSet = %v, want exactly alice, bob and carol", set)
+	}
+	if missing := config.GetSet("missing", ","); missing == nil || len(missing) != 0 {
+		t.Errorf("GetSet of a missing key = %v, want an empty set", missing)
+	}
+}
+
+func TestDurationDefaultUnitHintsOnce(t *testing.T) {
+	logger := &testLogger{}
+	config, path := newTestConfiguration(t, "bare=30\n", WithLogger(logger), WithLogging(true))
+	for i := 0; i < 3; i++ {
+		config.GetDurationDefaultUnit("bare", time.Second)
+	}
+	writeFile(t, path, "bare=45\n")
+	config.Reload()
+	config.GetDurationDefaultUnit("bare", time.Second)
+	hints := 0
+	logger.mutex.Lock()
+	for _, line := range logger.lines {
+		if strings.Contains(line, "bare number") {
+			hints++
+		}
+	}
+	logger.mutex.Unlock()
+	if hints != 2 {
+		t.Errorf("logged the bare-number hint %d times, want once per value", hints)
+	}
+	quiet := &testLogger{}
+	silenced, _ := newTestConfiguration(t, "bare=30\n", WithLogger(quiet), WithLogging(false))
+	silenced.GetDurationDefaultUnit("bare", time.Second)
+	if quiet.contains("bare number") {
+		t.Error("the bare-number hint was logged with logging disabled")
+	}
+}
@@ -0,0 +1,70 @@
+package configuration
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// SetCacheFile keeps a copy of the last successfully loaded parameters at
+// filename, as a JSON object so that any value survives the round trip. If
+// the primary file has not been loaded yet because it is missing or
+// unreadable, the cached parameters are loaded in its place.
+func (c *Configuration) SetCacheFile(filename string) {
+	c.mutex.Lock()
+	defer c.release()
+	c.cachefile = filename
+	if c.lastupdate > 0 {
+		c.writeCache()
+		return
+	}
+	f, err := os.Open(filename)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			c.logf("Configuration::SetCacheFile error opening %s: %v\n", filename, err)
+		}
+		return
+	}
+	defer f.Close()
+	c.logf("Configuration::SetCacheFile %s unavailable, loading cached configuration from %s\n", c.filename, filename)
+	cached := make(map[string]string)
+	if err := json.NewDecoder(f).Decode(&cached); err != nil {
+		c.logf("Configuration::SetCacheFile error reading %s: %v\n", filename, err)
+		return
+	}
+	entries := make([]entry, 0, len(cached))
+	for _, key := range sortedKeys(cached) {
+		entries = append(entries, entry{key: key, value: cached[key], file: filename})
+	}
+	// The cache stands in for the primary file without counting as a load
+	// of it, so LastUpdated and Status still show that none has succeeded.
+	loaded, reloads := c.lastloaded, c.reloads
+	if err := c.apply("SetCacheFile", filename, entries, false); err == nil {
+		c.lastloaded, c.reloads = loaded, reloads
+	}
+}
+
+func (c *Configuration) writeCache() {
+	if len(c.cachefile) == 0 {
+		return
+	} else if err := c.writeCacheFile(); err != nil {
+		c.logf("Configuration::writeCache error writing %s: %v\n", c.cachefile, err)
+	}
+}
+
+func (c *Configuration) writeCacheFile() error {
+	f, err := os.CreateTemp(filepath.Dir(c.cachefile), filepath.Base(c.cachefile)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	encoder := json.NewEncoder(f)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(c.parameters); err != nil {
+		f.Close()
+		return err
+	} else if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), c.cachefile)
+}
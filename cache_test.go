@@ -0,0 +1,39 @@
+package configuration
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCacheFileRestoresLastGood(t *testing.T) {
+	config, path := newTestConfiguration(t, "host=db.internal\nquery=a=b:c\nmotd=\"  padded  \"\n")
+	cache := filepath.Join(t.TempDir(), "last-good.json")
+	config.SetCacheFile(cache)
+	config.Close()
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+
+	logger := &testLogger{}
+	restarted := NewWithOptions(context.Background(), path, WithLogger(logger), WithPollInterval(time.Hour))
+	defer restarted.Close()
+	restarted.SetCacheFile(cache)
+	if got := restarted.Get("host"); got != "db.internal" {
+		t.Errorf("host = %q from the cache, want db.internal", got)
+	} else if got := restarted.Get("query"); got != "a=b:c" {
+		t.Errorf("query = %q from the cache, want a=b:c", got)
+	} else if got := restarted.Get("motd"); got != "  padded  " {
+		t.Errorf("motd = %q from the cache, want its whitespace kept", got)
+	}
+	if status := restarted.Status(); !status.LastSuccess.IsZero() || status.Reloads != 0 {
+		t.Errorf("Status after loading the cache = %+v, want no successful load", status)
+	} else if !restarted.LastUpdated().IsZero() {
+		t.Errorf("LastUpdated = %v after loading the cache, want zero", restarted.LastUpdated())
+	}
+	if !logger.contains("loading cached configuration") {
+		t.Error("loading from the cache was not logged")
+	}
+}
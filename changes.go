@@ -0,0 +1,63 @@
+package configuration
+
+import (
+	"sort"
+	"time"
+)
+
+type Change struct {
+	Key string `json:"key"`
+	Old string `json:"old,omitempty"`
+	New string `json:"new,omitempty"`
+}
+
+// ChangeSet is the net difference one reload made to the parameters, with
+// each list sorted by key.
+type ChangeSet struct {
+	Time    time.Time `json:"time"`
+	Source  string    `json:"source"`
+	Added   []Change  `json:"added,omitempty"`
+	Updated []Change  `json:"updated,omitempty"`
+	Removed []Change  `json:"removed,omitempty"`
+}
+
+func (s ChangeSet) Empty() bool {
+	return len(s.Added) == 0 && len(s.Updated) == 0 && len(s.Removed) == 0
+}
+
+// Keys returns every key the ChangeSet touches, sorted.
+func (s ChangeSet) Keys() []string {
+	keys := make([]string, 0, len(s.Added)+len(s.Updated)+len(s.Removed))
+	for _, changes := range [][]Change{s.Added, s.Updated, s.Removed} {
+		for _, change := range changes {
+			keys = append(keys, change.Key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// LastChanges returns the ChangeSet of the most recent reload.
+func (c *Configuration) LastChanges() ChangeSet {
+	c = c.origin()
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.lastChanges
+}
+
+func diff(now time.Time, source string, previous, current map[string]string) ChangeSet {
+	set := ChangeSet{Time: now, Source: source}
+	for _, key := range sortedKeys(current) {
+		if old, found := previous[key]; !found {
+			set.Added = append(set.Added, Change{Key: key, New: current[key]})
+		} else if old != current[key] {
+			set.Updated = append(set.Updated, Change{Key: key, Old: old, New: current[key]})
+		}
+	}
+	for _, key := range sortedKeys(previous) {
+		if _, found := current[key]; !found {
+			set.Removed = append(set.Removed, Change{Key: key, Old: previous[key]})
+		}
+	}
+	return set
+}
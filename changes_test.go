@@ -0,0 +1,37 @@
+package configuration
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestLastChanges(t *testing.T) {
+	config, path := newTestConfiguration(t, "kept=1\nupdated=1\nremoved=1\n", WithPruneRemoved())
+	var sets []ChangeSet
+	config.OnChangeSet(func(set ChangeSet) {
+		sets = append(sets, set)
+	})
+	writeFile(t, path, "kept=1\nupdated=2\nadded=1\n")
+	config.Reload()
+	set := config.LastChanges()
+	if set.Source != path {
+		t.Errorf("Source = %s, want %s", set.Source, path)
+	}
+	if want := []Change{{Key: "added", New: "1"}}; !slices.Equal(set.Added, want) {
+		t.Errorf("Added = %v, want %v", set.Added, want)
+	} else if want := []Change{{Key: "updated", Old: "1", New: "2"}}; !slices.Equal(set.Updated, want) {
+		t.Errorf("Updated = %v, want %v", set.Updated, want)
+	} else if want := []Change{{Key: "removed", Old: "1"}}; !slices.Equal(set.Removed, want) {
+		t.Errorf("Removed = %v, want %v", set.Removed, want)
+	}
+	if want := []string{"added", "removed", "updated"}; !slices.Equal(set.Keys(), want) {
+		t.Errorf("Keys = %v, want %v", set.Keys(), want)
+	}
+	if len(sets) != 1 || !slices.Equal(sets[0].Keys(), set.Keys()) {
+		t.Errorf("OnChangeSet got %v, want the LastChanges set", sets)
+	}
+	config.Reload()
+	if !config.LastChanges().Empty() {
+		t.Errorf("LastChanges after an unchanged reload = %+v, want empty", config.LastChanges())
+	}
+}
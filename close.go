@@ -0,0 +1,22 @@
+package configuration
+
+// Close stops the maintenance goroutine along with any WatchNotify and
+// ReloadOnSignal handlers, and closes the event log. Later calls to Update
+// and Reload do nothing, while the loaded parameters stay readable. Close
+// is safe to call more than once.
+func (c *Configuration) Close() error {
+	if !c.closed.CompareAndSwap(false, true) {
+		return nil
+	}
+	if c.cancel != nil {
+		c.cancel()
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.eventlog != nil {
+		err := c.eventlog.Close()
+		c.eventlog = nil
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package configuration
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestClose(t *testing.T) {
+	config, path := newTestConfiguration(t, "key=old\n")
+	if err := config.SetEventLog(filepath.Join(t.TempDir(), "events.jsonl")); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.Close(); err != nil {
+		t.Errorf("Close = %v", err)
+	} else if err := config.Close(); err != nil {
+		t.Errorf("a second Close = %v, want nil", err)
+	}
+	writeFile(t, path, "key=new\n")
+	config.Reload()
+	config.Update()
+	if got := config.Get("key"); got != "old" {
+		t.Errorf("key = %q after Close and a reload, want old", got)
+	}
+	config.mutex.RLock()
+	defer config.mutex.RUnlock()
+	if config.eventlog != nil {
+		t.Error("Close left the event log open")
+	}
+}
@@ -0,0 +1,76 @@
+package configuration
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+type computed struct {
+	raw   string
+	value any
+}
+
+// GetOrCompute caches the result of compute for the current raw value of
+// key, recomputing only after the value changes. Errors are not cached.
+func (c *Configuration) GetOrCompute(key string, compute func(raw string) (any, error)) (any, error) {
+	raw, err := c.lookup(key)
+	if err != nil {
+		return nil, err
+	}
+	c.computeMutex.Lock()
+	cached, found := c.computed[key]
+	c.computeMutex.Unlock()
+	if found && cached.raw == raw {
+		return cached.value, nil
+	}
+	value, err := compute(raw)
+	if err != nil {
+		return nil, err
+	}
+	c.computeMutex.Lock()
+	defer c.computeMutex.Unlock()
+	if c.computed == nil {
+		c.computed = make(map[string]computed)
+	}
+	c.computed[key] = computed{raw: raw, value: value}
+	return value, nil
+}
+
+type cachedFile struct {
+	modtime time.Time
+	data    []byte
+}
+
+// GetFileContent treats the value of key as a path and returns the file's
+// contents, rereading it only when its modification time changes.
+func (c *Configuration) GetFileContent(key string) ([]byte, error) {
+	path, err := c.lookup(key)
+	if err != nil {
+		return nil, err
+	}
+	path = strings.TrimSpace(path)
+	stat, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("key '%s': %w", key, err)
+	}
+	c.computeMutex.Lock()
+	cached, found := c.files[path]
+	c.computeMutex.Unlock()
+	if found && cached.modtime.Equal(stat.ModTime()) {
+		return bytes.Clone(cached.data), nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("key '%s': %w", key, err)
+	}
+	c.computeMutex.Lock()
+	defer c.computeMutex.Unlock()
+	if c.files == nil {
+		c.files = make(map[string]cachedFile)
+	}
+	c.files[path] = cachedFile{modtime: stat.ModTime(), data: data}
+	return bytes.Clone(data), nil
+}
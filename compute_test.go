@@ -0,0 +1,77 @@
+package configuration
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetOrCompute(t *testing.T) {
+	config, _ := newTestConfiguration(t, "pattern=a,b\n")
+	calls := 0
+	compute := func(raw string) (any, error) {
+		calls++
+		return strings.Split(raw, ","), nil
+	}
+	for i := 0; i < 10; i++ {
+		if _, err := config.GetOrCompute("pattern", compute); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("compute ran %d times for an unchanged value, want 1", calls)
+	}
+	config.SetKeyValue("pattern", "a,b,c")
+	value, err := config.GetOrCompute("pattern", compute)
+	if err != nil {
+		t.Fatal(err)
+	} else if calls != 2 || len(value.([]string)) != 3 {
+		t.Errorf("after a change compute ran %d times and returned %v", calls, value)
+	}
+	failing := func(string) (any, error) {
+		calls++
+		return nil, errors.New("compute failed")
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := config.GetOrCompute("other", failing); err == nil {
+			t.Error("GetOrCompute of a missing key succeeded")
+		}
+	}
+	config.SetKeyValue("other", "x")
+	calls = 0
+	for i := 0; i < 2; i++ {
+		if _, err := config.GetOrCompute("other", failing); err == nil {
+			t.Error("GetOrCompute swallowed a compute error")
+		}
+	}
+	if calls != 2 {
+		t.Errorf("a failing compute ran %d times, want 2 since errors are not cached", calls)
+	}
+}
+
+func TestGetFileContent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secret")
+	writeFile(t, path, "first")
+	config, _ := newTestConfiguration(t, "")
+	config.SetKeyValue("secret_file", path)
+	if got, err := config.GetFileContent("secret_file"); err != nil || string(got) != "first" {
+		t.Errorf("GetFileContent = %q, %v, want first", got, err)
+	}
+	writeFile(t, path, "second")
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := config.GetFileContent("secret_file"); err != nil || string(got) != "second" {
+		t.Errorf("GetFileContent = %q, %v after the file changed, want second", got, err)
+	}
+	config.SetKeyValue("gone_file", filepath.Join(filepath.Dir(path), "gone"))
+	if _, err := config.GetFileContent("gone_file"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("GetFileContent of a missing file = %v, want %v", err, os.ErrNotExist)
+	} else if _, err := config.GetFileContent("missing"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("GetFileContent of a missing key = %v, want %v", err, ErrKeyNotFound)
+	}
+}
@@ -1,15 +1,22 @@
 package configuration
 
 import (
-	"bufio"
+	"bytes"
 	"context"
+	"crypto/sha256"
 	"errors"
-	"log"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"math/rand"
 	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
+	"unicode"
 
 	"github.com/sharkpick/channels"
 )
@@ -19,62 +26,380 @@ var (
 	DefaultShouldLog = true
 )
 
+const DefaultDelimiters = "=:"
+
+type conditional struct {
+	whenKey, whenValue, value string
+}
+
 type Configuration struct {
 	filename         string
 	lastupdate       int64
+	includes         []dependency
+	target           string
+	hash             [sha256.Size]byte
+	paths            []string
+	mtimes           map[string]int64
+	lastloaded       time.Time
+	now              func() time.Time
+	cachefile        string
+	origins          map[string]origin
+	pending          []Event
+	subscribers      []*subscriber
+	nextSubscriber   int
+	eventlog         *os.File
+	locked           map[string]struct{}
+	modified         map[string]time.Time
+	defaults         map[string]string
+	loaded           map[string]string
+	loadedOrigins    map[string]origin
+	overrides        map[string]struct{}
+	layers           []Source
+	merged           map[string]string
+	summary          ParseSummary
+	format           Format
+	formatOverride   bool
+	delimiters       atomic.Value
+	envOverlay       bool
+	envPrefix        string
+	profile          string
 	parameters       map[string]string
+	validators       []Validator
+	deprecated       map[string]string
+	conditionals     map[string][]conditional
+	derived          map[string]func(c *Configuration) string
+	depth            int
+	root             *Configuration
+	reloadErrorHooks []func(error)
+	beforeHooks      []func(source string)
+	beforeSource     string
+	hooksRan         bool
+	swapped          bool
+	afterHooks       []func(changed []string)
+	changeSetHooks   []func(ChangeSet)
+	afterPending     []ChangeSet
+	lastChanges      ChangeSet
+	history          []Revision
+	historySize      int
+	lastError        error
+	lastErrorTime    time.Time
+	reloads          int64
+	failures         int64
+	revalidateEvery  atomic.Int64
+	invalid          atomic.Bool
+	lastPoll         atomic.Int64
+	pollInterval     atomic.Int64
+	jitter           atomic.Uint64
+	pace             atomic.Int64
+	warnedPace       atomic.Pointer[time.Duration]
+	version          atomic.Int64
+	debounce         atomic.Int64
+	errorThreshold   atomic.Int64
+	thresholdSet     atomic.Bool
+	logger           Logger
+	parser           Parser
+	reloading        atomic.Bool
+	forced           atomic.Bool
+	changed          chan struct{}
+	wake             chan struct{}
+	done             <-chan struct{}
+	notifying        atomic.Bool
+	cancel           context.CancelFunc
+	closed           atomic.Bool
 	mutex            sync.RWMutex
+	bound            sync.RWMutex
+	computed         map[string]computed
+	files            map[string]cachedFile
+	hinted           map[[2]string]struct{}
+	computeMutex     sync.Mutex
 	ShouldLogUpdates atomic.Bool
+	StrictDelimiter  atomic.Bool
+	StrictKeys       atomic.Bool
+	// PreserveWhitespace keeps the whitespace around the delimiter as part
+	// of the key and value, as in "foo = bar" storing "foo " and " bar".
+	PreserveWhitespace atomic.Bool
+	// ExpandEnv replaces ${NAME} in loaded values with the environment
+	// variable NAME; $$ stands for a literal $.
+	ExpandEnv atomic.Bool
+	// InterpolateKeys replaces ${key} in loaded values with the value of
+	// another key, as in log_dir=${base_dir}/logs.
+	InterpolateKeys atomic.Bool
+	// ReplayOnSubscribe delivers an Added event for every loaded key to
+	// each new subscriber as it registers.
+	ReplayOnSubscribe atomic.Bool
+	// FollowSymlinks resolves the file through any symlinks on every poll
+	// and reloads whenever the target changes, which is how Kubernetes
+	// swaps a mounted ConfigMap through its ..data link.
+	FollowSymlinks atomic.Bool
+	// HashContents detects changes by a SHA-256 of the file contents rather
+	// than its modification time, for filesystems with coarse timestamps
+	// and tools that preserve them.
+	HashContents atomic.Bool
+	// PruneRemoved removes keys that are no longer in the file when it is
+	// reloaded. Runtime overrides, defaults and locked keys are kept.
+	PruneRemoved atomic.Bool
 }
 
 var (
 	ErrEmptyParameter = errors.New("empty parameter")
+	ErrKeyNotFound    = errors.New("key not found")
 )
 
-func (c *Configuration) SetFilename(filename string) {
+// SetFilename points c at filename. A new file is parsed and validated
+// before anything is replaced; on error the current file and parameters
+// are kept. A file that does not exist yet is switched to at once and
+// loaded, in place of the current parameters, when it appears.
+func (c *Configuration) SetFilename(filename string) error {
+	c.mutex.RLock()
+	same := c.filename == filename
+	c.mutex.RUnlock()
+	if same {
+		return c.reload(false)
+	} else if _, err := os.Stat(filename); !errors.Is(err, os.ErrNotExist) {
+		return c.SwapFile(filename)
+	}
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
-	if c.filename != filename {
-		c.filename = filename
-		c.lastupdate = 0
-	}
-	c.update()
+	c.filename, c.lastupdate, c.includes, c.target, c.hash = filename, 0, nil, "", [sha256.Size]byte{}
+	c.swapped = true
+	c.logf("Configuration::SetFilename %s does not exist yet, waiting for it\n", filename)
+	return nil
 }
 
 func (c *Configuration) SetKeyValue(key, value string) {
+	c.mutex.Lock()
+	defer c.release()
+	delete(c.origins, key)
+	c.override(key)
+	if c.store("SetKeyValue", key, value) {
+		c.warnDeprecated("SetKeyValue", key)
+	}
+}
+
+// Delete removes key along with any runtime override of it. A key that is
+// still in the file returns on the next reload that changes the file.
+func (c *Configuration) Delete(key string) {
+	c.mutex.Lock()
+	defer c.release()
+	delete(c.overrides, key)
+	c.remove("Delete", key)
+}
+
+// Clear removes every parameter and runtime override.
+func (c *Configuration) Clear() {
+	c.mutex.Lock()
+	defer c.release()
+	c.overrides = nil
+	for _, key := range sortedKeys(c.parameters) {
+		c.remove("Clear", key)
+	}
+}
+
+func (c *Configuration) Deprecate(oldKey, message string) {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
+	if c.deprecated == nil {
+		c.deprecated = make(map[string]string)
+	}
+	c.deprecated[oldKey] = message
+}
+
+func (c *Configuration) warnDeprecated(caller, key string) {
+	if message, found := c.deprecated[key]; found {
+		c.logf("Configuration::%s key '%s' is deprecated: %s\n", caller, escape(key), message)
+	}
+}
+
+func (c *Configuration) store(caller, key, value string) bool {
 	if stored, found := c.parameters[key]; found && stored == value {
-		return
+		return false
 	} else if !found && c.ShouldLogUpdates.Load() {
-		log.Printf("Configuration::SetKeyValue storing key '%s' with value '%s'\n", key, value)
+		c.logf("Configuration::%s storing key '%s' with value '%s'\n", caller, escape(key), escape(value))
 	} else if found && c.ShouldLogUpdates.Load() {
-		log.Printf("Configuration::SetKeyValue updating key '%s' value from '%s' to '%s'\n", key, stored, value)
+		c.logf("Configuration::%s updating key '%s' value from '%s' to '%s'\n", caller, escape(key), escape(stored), escape(value))
+	}
+	if stored, found := c.parameters[key]; found {
+		c.record(Event{Kind: Updated, Key: key, Old: stored, New: value, Source: caller})
+	} else {
+		c.record(Event{Kind: Added, Key: key, New: value, Source: caller})
+	}
+	if c.modified == nil {
+		c.modified = make(map[string]time.Time)
 	}
 	c.parameters[key] = value
+	c.modified[key] = c.clock()
+	c.notify()
+	return true
+}
+
+func escape(s string) string {
+	var builder strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\n':
+			builder.WriteString(`\n`)
+		case r == '\r':
+			builder.WriteString(`\r`)
+		case r == '\t':
+			builder.WriteString(`\t`)
+		case unicode.IsControl(r):
+			fmt.Fprintf(&builder, `\x%02x`, r)
+		default:
+			builder.WriteRune(r)
+		}
+	}
+	return builder.String()
+}
+
+func (c *Configuration) remove(caller, key string) bool {
+	if stored, found := c.parameters[key]; !found {
+		return false
+	} else if c.ShouldLogUpdates.Load() {
+		c.logf("Configuration::%s removing key '%s' with value '%s'\n", caller, escape(key), escape(stored))
+	}
+	c.record(Event{Kind: Removed, Key: key, Old: c.parameters[key], Source: caller})
+	delete(c.parameters, key)
+	delete(c.origins, key)
+	delete(c.modified, key)
+	c.notify()
+	return true
+}
+
+func (c *Configuration) notify() {
+	if c.changed != nil {
+		close(c.changed)
+		c.changed = nil
+	}
+}
+
+func (c *Configuration) GetWhenSet(ctx context.Context, key string) (string, error) {
+	for {
+		if value, found := c.get(key); found {
+			return value, nil
+		}
+		c.mutex.Lock()
+		if value, found := c.resolve(key); found {
+			c.mutex.Unlock()
+			return value, nil
+		} else if c.changed == nil {
+			c.changed = make(chan struct{})
+		}
+		changed := c.changed
+		c.mutex.Unlock()
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+}
+
+// WaitForKey blocks until key exists, for services that start before
+// their configuration has been fully provisioned. It returns ctx.Err() if
+// ctx ends first.
+func (c *Configuration) WaitForKey(ctx context.Context, key string) (string, error) {
+	return c.GetWhenSet(ctx, key)
 }
 
 func (c *Configuration) Get(key string) string {
-	c.mutex.RLock()
-	defer c.mutex.RUnlock()
-	return c.parameters[key]
+	value, _ := c.get(key)
+	return value
+}
+
+func (c *Configuration) Lookup(key string) (string, bool) {
+	return c.get(key)
+}
+
+// Has reports whether key is set, telling a missing key apart from one set
+// to the empty string.
+func (c *Configuration) Has(key string) bool {
+	_, found := c.get(key)
+	return found
+}
+
+func (c *Configuration) get(key string) (string, bool) {
+	root := c.origin()
+	root.mutex.RLock()
+	value, found := root.resolve(key)
+	fn, derived := root.derived[key]
+	root.mutex.RUnlock()
+	if found || !derived {
+		return value, found
+	} else if c.depth >= maxDerivedDepth {
+		c.logf("Configuration::Get derived key '%s' exceeded depth %d, possible recursion\n", escape(key), maxDerivedDepth)
+		return "", false
+	} else {
+		return fn(c.view()), true
+	}
+}
+
+func (c *Configuration) lookup(key string) (string, error) {
+	if value, found := c.get(key); !found {
+		return "", fmt.Errorf("%w: '%s'", ErrKeyNotFound, key)
+	} else {
+		return value, nil
+	}
+}
+
+func (c *Configuration) resolve(key string) (string, bool) {
+	if value, found := c.parameters[key]; found {
+		return value, true
+	}
+	for _, condition := range c.conditionals[key] {
+		if c.parameters[condition.whenKey] == condition.whenValue {
+			return condition.value, true
+		}
+	}
+	return "", false
+}
+
+func (c *Configuration) SetConditionalDefault(key, whenKey, whenValue, defaultValue string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.conditionals == nil {
+		c.conditionals = make(map[string][]conditional)
+	}
+	c.conditionals[key] = append(c.conditionals[key], conditional{whenKey: whenKey, whenValue: whenValue, value: defaultValue})
+}
+
+// GetWithTimeout returns fallback if the lock cannot be taken within
+// timeout. A derived key is computed as Get would, without a timeout.
+func (c *Configuration) GetWithTimeout(key string, timeout time.Duration, fallback string) string {
+	root, deadline := c.origin(), time.Now().Add(timeout)
+	for !root.mutex.TryRLock() {
+		if !time.Now().Before(deadline) {
+			return fallback
+		}
+		time.Sleep(min(time.Millisecond, time.Until(deadline)))
+	}
+	value, found := root.resolve(key)
+	_, derived := root.derived[key]
+	root.mutex.RUnlock()
+	if !found && derived {
+		value, _ = c.get(key)
+	}
+	return value
 }
 
 func (c *Configuration) GetSlice(keys []string) []string {
-	c.mutex.RLock()
-	defer c.mutex.RUnlock()
 	results := make([]string, 0, len(keys))
 	for _, key := range keys {
-		results = append(results, c.parameters[key])
+		results = append(results, c.Get(key))
 	}
 	return results
 }
 
 func SplitConfigurationFileLine(s string) ([2]string, error) {
+	return splitLine(s, DefaultDelimiters)
+}
+
+func splitLine(s, delimiters string) ([2]string, error) {
 	if s = strings.TrimSpace(s); len(s) == 0 {
 		return [2]string{}, ErrEmptyParameter
-	} else if i := strings.IndexAny(s, "=:"); i == -1 {
+	} else if i := strings.IndexAny(s, delimiters); i == -1 && delimiters == DefaultDelimiters {
 		return [2]string{}, errors.New("missing delimiter (':' or '=')")
+	} else if i == -1 {
+		return [2]string{}, fmt.Errorf("missing delimiter (one of '%s')", delimiters)
 	} else {
 		first, second := strings.Clone(s[:i]), strings.Clone(s[i+1:])
 		return [2]string{first, second}, nil
@@ -82,48 +407,249 @@ func SplitConfigurationFileLine(s string) ([2]string, error) {
 }
 
 func (c *Configuration) Update() {
+	c.reload(false)
+}
+
+func (c *Configuration) Reload() {
+	c.reload(true)
+}
+
+// reload coalesces concurrent triggers: if another reload is already in
+// progress the call returns without queueing a second pass, except that a
+// forced reload is remembered and run by the pass in progress once it ends.
+func (c *Configuration) reload(force bool) error {
+	if force {
+		c.forced.Store(true)
+	}
+	if c.closed.Load() || !c.reloading.CompareAndSwap(false, true) {
+		return nil
+	}
+	for {
+		err := c.reloadPass(c.forced.Swap(false))
+		c.reloading.Store(false)
+		if c.closed.Load() || !c.forced.Load() || !c.reloading.CompareAndSwap(false, true) {
+			return err
+		}
+	}
+}
+
+// reloadPass runs one reload. When BeforeReload hooks are registered,
+// update stops short of applying a change; the hooks then run without the
+// lock and update starts over, so nothing read before the hooks is applied
+// after them.
+func (c *Configuration) reloadPass(force bool) error {
 	c.mutex.Lock()
-	defer c.mutex.Unlock()
-	c.update()
+	if force {
+		c.lastupdate = 0
+	}
+	err := c.update()
+	if errors.Is(err, errBeforeReload) {
+		source, hooks := c.beforeSource, append([]func(string){}, c.beforeHooks...)
+		c.release()
+		for _, hook := range hooks {
+			hook(source)
+		}
+		c.mutex.Lock()
+		if force {
+			c.lastupdate = 0
+		}
+		c.hooksRan = true
+		err = c.update()
+		c.hooksRan = false
+	}
+	c.release()
+	c.reloadError(err)
+	return err
 }
 
-func (c *Configuration) update() {
-	if stat, err := os.Stat(c.filename); err != nil {
+func (c *Configuration) update() error {
+	if c.layers != nil {
+		return c.updateLayers()
+	} else if c.paths != nil {
+		return c.updateMulti()
+	}
+	path, target, retargeted := c.filename, c.target, false
+	if c.FollowSymlinks.Load() {
+		if resolved, err := filepath.EvalSymlinks(c.filename); err == nil {
+			path, target, retargeted = resolved, resolved, resolved != c.target
+		}
+	}
+	stat, err := os.Stat(path)
+	if err != nil {
 		if !errors.Is(err, os.ErrNotExist) {
-			log.Printf("Configuration::Update error opening %s: %v\n", c.filename, err)
+			c.logf("Configuration::Update error opening %s: %v\n", c.filename, err)
+			return err
 		}
-		return
-	} else if !stat.ModTime().After(time.Unix(0, c.lastupdate)) {
-		return
-	} else {
-		f, err := os.Open(c.filename)
+		return nil
+	}
+	changed, contents, sum := stat.ModTime().After(time.Unix(0, c.lastupdate)), io.Reader(nil), c.hash
+	if c.HashContents.Load() {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			c.logf("Configuration::Update error opening %s: %v\n", c.filename, err)
+			return err
+		}
+		sum = sha256.Sum256(data)
+		changed, contents = c.lastupdate == 0 || sum != c.hash, bytes.NewReader(data)
+	}
+	if !changed && !c.includesChanged() && !retargeted {
+		return nil
+	} else if c.settling(c.filename, stat.ModTime()) {
+		return nil
+	}
+	if err := c.beforeReload(c.filename); err != nil {
+		return err
+	}
+	if contents == nil {
+		f, err := os.Open(path)
 		if err != nil {
-			log.Printf("Configuration::Update error opening %s: %v\n", c.filename, err)
-			return
+			c.logf("Configuration::Update error opening %s: %v\n", c.filename, err)
+			return err
 		}
 		defer f.Close()
-		scanner := bufio.NewScanner(f)
-		for scanner.Scan() {
-			if split, err := SplitConfigurationFileLine(scanner.Text()); err != nil {
-				if !errors.Is(err, ErrEmptyParameter) {
-					if c.ShouldLogUpdates.Load() {
-						log.Printf("Configuration::update error parsing %s: %v\n", scanner.Text(), err)
-					}
-				}
-				continue
-			} else {
-				if stored, found := c.parameters[split[0]]; found && stored == split[1] {
-					continue
-				} else if !found && c.ShouldLogUpdates.Load() {
-					log.Printf("Configuration::update storing key '%s' with value '%s'\n", split[0], split[1])
-				} else if found && c.ShouldLogUpdates.Load() {
-					log.Printf("Configuration::update updating key '%s' value from '%s' to '%s'\n", split[0], stored, split[1])
-				}
-				c.parameters[split[0]] = split[1]
+		contents = f
+	}
+	result, err := c.decode("update", c.filename, c.formatFor(c.filename), contents)
+	if err != nil {
+		c.logf("Configuration::update error reading %s: %v\n", c.filename, err)
+		return err
+	}
+	c.lastupdate, c.includes, c.target, c.hash = stat.ModTime().UnixNano(), result.includes, target, sum
+	c.summarize("update", c.filename, result)
+	if err := c.tolerate("update", c.filename, result.errors, -1); err != nil {
+		return err
+	} else if err := c.apply("update", c.filename, result.entries, c.swapped || c.PruneRemoved.Load()); err != nil {
+		return err
+	}
+	c.swapped = false
+	c.writeCache()
+	return nil
+}
+
+func (c *Configuration) apply(caller, source string, entries []entry, replace bool) error {
+	entries, defaults := c.withDefaults(c.profiled(entries), replace)
+	sourced, err := c.interpolate(c.overlay(entries))
+	if err != nil {
+		c.logf("Configuration::%s rejecting %s: %v\n", caller, source, err)
+		return err
+	}
+	entries = c.overridden(caller, c.unlocked(caller, sourced))
+	staged := make(map[string]string, len(c.parameters))
+	for key, value := range c.parameters {
+		_, locked := c.locked[key]
+		if _, overridden := c.overrides[key]; locked || overridden || !replace {
+			staged[key] = value
+		}
+	}
+	for _, e := range entries {
+		staged[e.key] = e.value
+	}
+	if err := c.validate(staged); err != nil {
+		c.logf("Configuration::%s rejecting %s: %v\n", caller, source, err)
+		return err
+	}
+	c.invalid.Store(false)
+	c.lastloaded = c.clock()
+	c.reloads++
+	c.remember(sourced, defaults, replace)
+	defer c.reloaded(source, maps.Clone(c.parameters))
+	if replace {
+		for key := range c.parameters {
+			if _, found := staged[key]; !found {
+				c.remove(caller, key)
 			}
 		}
-		c.lastupdate = stat.ModTime().UnixNano()
 	}
+	if c.origins == nil {
+		c.origins = make(map[string]origin)
+	}
+	warned := make(map[string]struct{})
+	for _, e := range entries {
+		c.store(caller, e.key, e.value)
+		c.origins[e.key] = origin{file: e.file, line: e.line}
+		if _, found := warned[e.key]; !found {
+			warned[e.key] = struct{}{}
+			c.warnDeprecated(caller, e.key)
+		}
+	}
+	return nil
+}
+
+func (c *Configuration) clock() time.Time {
+	if c.now != nil {
+		return c.now()
+	}
+	return time.Now()
+}
+
+func (c *Configuration) LastUpdated() time.Time {
+	c = c.origin()
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.lastloaded
+}
+
+// Age reports how long ago the parameters were last loaded. A Configuration
+// that has never loaded reports the time since the process started.
+func (c *Configuration) Age() time.Duration {
+	if updated := c.LastUpdated(); updated.IsZero() {
+		return c.clock().Sub(processStart)
+	} else {
+		return c.clock().Sub(updated)
+	}
+}
+
+func (c *Configuration) poll() {
+	now := time.Now().UnixNano()
+	if previous := c.lastPoll.Swap(now); previous != 0 {
+		c.pollInterval.Store(now - previous)
+	}
+}
+
+// SetJitter randomizes each poll interval by up to +/- fraction of
+// MaintenancePace so instances sharing a file don't poll in lockstep.
+func (c *Configuration) SetJitter(fraction float64) {
+	c.jitter.Store(math.Float64bits(max(0, min(fraction, 1))))
+}
+
+func (c *Configuration) nextPace() time.Duration {
+	pace := MaintenancePace
+	if p := c.pace.Load(); p != 0 {
+		pace = time.Duration(p)
+	}
+	return jittered(c.effectivePace(pace), math.Float64frombits(c.jitter.Load()), rand.Float64())
+}
+
+const (
+	defaultPace = time.Second
+	minimumPace = 10 * time.Millisecond
+)
+
+var processStart = time.Now()
+
+// effectivePace guards against a pace that would busy-loop or panic: a
+// non-positive pace falls back to defaultPace and anything shorter than
+// minimumPace is clamped up to it.
+func (c *Configuration) effectivePace(pace time.Duration) time.Duration {
+	effective := pace
+	if pace <= 0 {
+		effective = defaultPace
+	} else if pace < minimumPace {
+		effective = minimumPace
+	}
+	if warned := c.warnedPace.Load(); effective != pace && (warned == nil || *warned != pace) {
+		c.warnedPace.Store(&pace)
+		c.logf("Configuration::effectivePace pace %v is invalid, using %v\n", pace, effective)
+	}
+	return effective
+}
+
+func jittered(pace time.Duration, fraction, r float64) time.Duration {
+	return time.Duration(float64(pace) * (1 + fraction*(2*r-1)))
+}
+
+func (c *Configuration) LastPollInterval() time.Duration {
+	return time.Duration(c.pollInterval.Load())
 }
 
 func New(filename string, shouldLog ...bool) *Configuration {
@@ -131,29 +657,41 @@ func New(filename string, shouldLog ...bool) *Configuration {
 }
 
 func NewWithContext(ctx context.Context, filename string, shouldLog ...bool) *Configuration {
-	config := &Configuration{
-		filename: filename,
+	if len(shouldLog) == 1 {
+		return NewWithOptions(ctx, filename, WithLogging(shouldLog[0]))
 	}
-	config.ShouldLogUpdates.Store(func() bool {
-		if len(shouldLog) == 1 {
-			return shouldLog[0]
-		} else {
-			return DefaultShouldLog
-		}
-	}())
-	config.update()
+	return NewWithOptions(ctx, filename)
+}
+
+func (c *Configuration) start(ctx context.Context) {
+	ctx, c.cancel = context.WithCancel(ctx)
+	c.mutex.Lock()
+	if len(c.profile) == 0 {
+		c.profile = profileFromEnv()
+	}
+	c.wake, c.done = make(chan struct{}, 1), ctx.Done()
+	c.mutex.Unlock()
+	c.reload(false)
 	go func() {
-		ticker := time.NewTicker(MaintenancePace)
-		defer ticker.Stop()
+		lastRevalidate := time.Now()
 		for channels.ContextNotDone(ctx) {
-			after := time.After(MaintenancePace)
+			pace := c.nextPace()
+			if c.notifying.Load() {
+				pace = NotifyFallbackPace
+			}
 			select {
-			case <-after:
-				config.Update()
+			case <-time.After(pace):
+				c.poll()
+				c.Update()
+				if every := time.Duration(c.revalidateEvery.Load()); every > 0 && time.Since(lastRevalidate) >= every {
+					lastRevalidate = time.Now()
+					c.Revalidate()
+				}
+			case <-c.wake:
+				c.Update()
 			case <-ctx.Done():
 				return
 			}
 		}
 	}()
-	return config
 }
@@ -0,0 +1,533 @@
+package configuration
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// writeFile writes contents to path, moving its modification time forward
+// if the write landed within the same tick as the previous one so that the
+// change is always noticed.
+func writeFile(t *testing.T, path, contents string) {
+	t.Helper()
+	previous, statErr := os.Stat(path)
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatal(err)
+	} else if statErr != nil {
+		return
+	}
+	if stat, err := os.Stat(path); err != nil {
+		t.Fatal(err)
+	} else if !stat.ModTime().After(previous.ModTime()) {
+		mtime := previous.ModTime().Add(time.Millisecond)
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// newTestConfiguration writes contents to a file in a temporary directory
+// and watches it until the test ends. The poll interval is long enough that
+// tests drive reloads themselves.
+func newTestConfiguration(t *testing.T, contents string, opts ...Option) (*Configuration, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "test.conf")
+	writeFile(t, path, contents)
+	opts = append([]Option{WithLogger(&testLogger{}), WithPollInterval(time.Hour)}, opts...)
+	config := NewWithOptions(context.Background(), path, opts...)
+	t.Cleanup(func() { config.Close() })
+	return config, path
+}
+
+func TestLastPollInterval(t *testing.T) {
+	const pace = 20 * time.Millisecond
+	config, _ := newTestConfiguration(t, "", WithPollInterval(pace))
+	deadline := time.Now().Add(5 * time.Second)
+	for config.LastPollInterval() == 0 && time.Now().Before(deadline) {
+		time.Sleep(pace)
+	}
+	if got := config.LastPollInterval(); got < pace || got > pace+200*time.Millisecond {
+		t.Errorf("LastPollInterval = %v, want about %v", got, pace)
+	}
+}
+
+// testLogger records log output so tests can assert on it.
+type testLogger struct {
+	mutex sync.Mutex
+	lines []string
+}
+
+func (l *testLogger) Printf(format string, v ...any) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.lines = append(l.lines, fmt.Sprintf(format, v...))
+}
+
+func (l *testLogger) contains(s string) bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	for _, line := range l.lines {
+		if strings.Contains(line, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestDeprecate(t *testing.T) {
+	logger := &testLogger{}
+	config, path := newTestConfiguration(t, "timeout=30s\n", WithLogger(logger))
+	config.Deprecate("old_timeout", "use timeout")
+	config.Reload()
+	if logger.contains("deprecated") {
+		t.Error("warned about a deprecated key that is absent")
+	}
+	writeFile(t, path, "old_timeout=30\n")
+	config.Reload()
+	if !logger.contains("key 'old_timeout' is deprecated: use timeout") {
+		t.Errorf("no deprecation warning in %q", logger.lines)
+	}
+	if got := config.Get("old_timeout"); got != "30" {
+		t.Errorf("old_timeout = %q, want 30", got)
+	}
+}
+
+func TestGetWithTimeout(t *testing.T) {
+	config, _ := newTestConfiguration(t, "key=value\n")
+	if got := config.GetWithTimeout("key", time.Second, "fallback"); got != "value" {
+		t.Errorf("GetWithTimeout = %q, want value", got)
+	}
+	config.mutex.Lock()
+	start := time.Now()
+	got := config.GetWithTimeout("key", 20*time.Millisecond, "fallback")
+	elapsed := time.Since(start)
+	config.mutex.Unlock()
+	if got != "fallback" {
+		t.Errorf("GetWithTimeout under the write lock = %q, want fallback", got)
+	} else if elapsed < 20*time.Millisecond {
+		t.Errorf("GetWithTimeout gave up after %v, before its timeout", elapsed)
+	}
+}
+
+func TestSetConditionalDefault(t *testing.T) {
+	config, _ := newTestConfiguration(t, "driver=postgres\n")
+	config.SetConditionalDefault("port", "driver", "postgres", "5432")
+	config.SetConditionalDefault("port", "driver", "mysql", "3306")
+	if got := config.Get("port"); got != "5432" {
+		t.Errorf("port = %q for postgres, want 5432", got)
+	}
+	config.SetKeyValue("driver", "mysql")
+	if got := config.Get("port"); got != "3306" {
+		t.Errorf("port = %q for mysql, want 3306", got)
+	}
+	config.SetKeyValue("port", "6000")
+	if got := config.Get("port"); got != "6000" {
+		t.Errorf("port = %q once set, want 6000", got)
+	}
+}
+
+// setClock replaces the clock c stamps loads and changes with.
+func (c *Configuration) setClock(now func() time.Time) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.now = now
+}
+
+func TestLastUpdatedUsesClock(t *testing.T) {
+	config, path := newTestConfiguration(t, "key=value\n")
+	fixed := time.Date(2020, time.January, 2, 3, 4, 5, 0, time.UTC)
+	config.setClock(func() time.Time { return fixed })
+	writeFile(t, path, "key=changed\n")
+	config.Update()
+	if got := config.LastUpdated(); !got.Equal(fixed) {
+		t.Errorf("LastUpdated = %v, want %v", got, fixed)
+	}
+}
+
+func TestLoggedValuesAreEscaped(t *testing.T) {
+	logger := &testLogger{}
+	config, _ := newTestConfiguration(t, "", WithLogger(logger), WithLogging(true))
+	config.SetKeyValue("motd", "hello\nConfiguration::forged line\r")
+	if got := config.Get("motd"); got != "hello\nConfiguration::forged line\r" {
+		t.Errorf("stored value = %q, want it unescaped", got)
+	}
+	if !logger.contains(`'hello\nConfiguration::forged line\r'`) {
+		t.Errorf("escaped value not logged in %q", logger.lines)
+	}
+	logger.mutex.Lock()
+	defer logger.mutex.Unlock()
+	for _, line := range logger.lines {
+		if strings.ContainsAny(strings.TrimSuffix(line, "\n"), "\r\n") {
+			t.Errorf("log line %q contains a raw line break", line)
+		}
+	}
+}
+
+func TestJitter(t *testing.T) {
+	const pace = time.Second
+	if got := jittered(pace, 0.2, 0); got != 800*time.Millisecond {
+		t.Errorf("jittered at the low end = %v, want 800ms", got)
+	} else if got := jittered(pace, 0.2, 1); got != 1200*time.Millisecond {
+		t.Errorf("jittered at the high end = %v, want 1.2s", got)
+	}
+	config, _ := newTestConfiguration(t, "", WithPollInterval(pace), WithJitter(0.2))
+	seen := make(map[time.Duration]struct{})
+	for i := 0; i < 100; i++ {
+		next := config.nextPace()
+		if next < 800*time.Millisecond || next > 1200*time.Millisecond {
+			t.Fatalf("nextPace = %v, outside 1s +/- 20%%", next)
+		}
+		seen[next] = struct{}{}
+	}
+	if len(seen) < 2 {
+		t.Error("nextPace did not vary with jitter set")
+	}
+}
+
+func TestGetWhenSet(t *testing.T) {
+	config, _ := newTestConfiguration(t, "")
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		config.SetKeyValue("token", "abc")
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if got, err := config.GetWhenSet(ctx, "token"); err != nil {
+		t.Fatal(err)
+	} else if got != "abc" {
+		t.Errorf("GetWhenSet = %q, want abc", got)
+	}
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := config.GetWhenSet(ctx, "never"); err != context.DeadlineExceeded {
+		t.Errorf("GetWhenSet of a key that never appears = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestConcurrentReloadsApplyOnce(t *testing.T) {
+	config, path := newTestConfiguration(t, "key=old\n")
+	var (
+		mutex   sync.Mutex
+		changes []string
+	)
+	config.Subscribe(func(event Event) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if event.Key == "key" {
+			changes = append(changes, event.Old+"->"+event.New)
+		}
+	})
+	writeFile(t, path, "key=new\n")
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			config.Reload()
+		}()
+		go func() {
+			defer wg.Done()
+			config.Update()
+		}()
+	}
+	wg.Wait()
+	config.Reload()
+	mutex.Lock()
+	defer mutex.Unlock()
+	if len(changes) != 1 || changes[0] != "old->new" {
+		t.Errorf("Subscribe saw %v, want a single old->new", changes)
+	}
+}
+
+func TestEffectivePace(t *testing.T) {
+	logger := &testLogger{}
+	config, _ := newTestConfiguration(t, "", WithLogger(logger))
+	tests := []struct {
+		pace time.Duration
+		want time.Duration
+	}{
+		{0, defaultPace},
+		{-time.Second, defaultPace},
+		{time.Nanosecond, minimumPace},
+		{time.Minute, time.Minute},
+	}
+	for _, test := range tests {
+		if got := config.effectivePace(test.pace); got != test.want {
+			t.Errorf("effectivePace(%v) = %v, want %v", test.pace, got, test.want)
+		}
+	}
+	if !logger.contains("pace -1s is invalid") {
+		t.Error("an invalid pace was not logged")
+	}
+	other := &testLogger{}
+	second, _ := newTestConfiguration(t, "", WithLogger(other))
+	second.effectivePace(-time.Second)
+	if !other.contains("pace -1s is invalid") {
+		t.Error("a pace already warned about by another Configuration was not logged")
+	}
+	clamped, _ := newTestConfiguration(t, "", WithPollInterval(time.Nanosecond))
+	if got := clamped.nextPace(); got != minimumPace {
+		t.Errorf("nextPace = %v for a 1ns interval, want %v", got, minimumPace)
+	}
+}
+
+func TestAge(t *testing.T) {
+	config, _ := newTestConfiguration(t, "key=value\n")
+	now := time.Date(2024, time.June, 1, 12, 0, 0, 0, time.UTC)
+	config.setClock(func() time.Time { return now })
+	config.Reload()
+	if got := config.Age(); got != 0 {
+		t.Errorf("Age = %v right after a reload, want 0", got)
+	}
+	later := now.Add(5 * time.Second)
+	config.setClock(func() time.Time { return later })
+	if got := config.Age(); got != 5*time.Second {
+		t.Errorf("Age = %v, want 5s", got)
+	}
+}
+
+func TestSetFilenameKeepsConfigOnBadFile(t *testing.T) {
+	config, path := newTestConfiguration(t, "key=good\n")
+	malformed := filepath.Join(filepath.Dir(path), "malformed.conf")
+	writeFile(t, malformed, "key=bad\nno delimiter here\n")
+	if err := config.SetFilename(malformed); err == nil {
+		t.Error("SetFilename accepted a malformed file")
+	}
+	if got := config.Get("key"); got != "good" {
+		t.Errorf("key = %q after a rejected SetFilename, want good", got)
+	} else if got := filenameOf(config); got != path {
+		t.Errorf("filename = %s after a rejected SetFilename, want %s", got, path)
+	}
+	valid := filepath.Join(filepath.Dir(path), "valid.conf")
+	writeFile(t, valid, "key=new\n")
+	if err := config.SetFilename(valid); err != nil {
+		t.Errorf("SetFilename of a valid file: %v", err)
+	} else if got := config.Get("key"); got != "new" {
+		t.Errorf("key = %q after SetFilename, want new", got)
+	} else if got := filenameOf(config); got != valid {
+		t.Errorf("filename = %s after SetFilename, want %s", got, valid)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	config, _ := newTestConfiguration(t, "set=value\nempty=\n")
+	if value, found := config.Lookup("set"); !found || value != "value" {
+		t.Errorf("Lookup(set) = %q, %v, want value, true", value, found)
+	}
+	if value, found := config.Lookup("empty"); !found || value != "" {
+		t.Errorf("Lookup(empty) = %q, %v, want an empty value that is found", value, found)
+	}
+	if value, found := config.Lookup("missing"); found || value != "" {
+		t.Errorf("Lookup(missing) = %q, %v, want not found", value, found)
+	}
+}
+
+func TestFollowSymlinks(t *testing.T) {
+	dir := t.TempDir()
+	first, second, link := filepath.Join(dir, "first.conf"), filepath.Join(dir, "second.conf"), filepath.Join(dir, "app.conf")
+	writeFile(t, second, "key=second\n")
+	past := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(second, past, past); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, first, "key=first\n")
+	if err := os.Symlink(first, link); err != nil {
+		t.Fatal(err)
+	}
+	config := NewWithOptions(context.Background(), link, WithLogger(&testLogger{}), WithFollowSymlinks())
+	defer config.Close()
+	if got := config.Get("key"); got != "first" {
+		t.Fatalf("key = %q through the symlink, want first", got)
+	}
+	swap := filepath.Join(dir, "app.conf.tmp")
+	if err := os.Symlink(second, swap); err != nil {
+		t.Fatal(err)
+	} else if err := os.Rename(swap, link); err != nil {
+		t.Fatal(err)
+	}
+	config.Update()
+	if got := config.Get("key"); got != "second" {
+		t.Errorf("key = %q after retargeting to an older file, want second", got)
+	}
+}
+
+func TestHashContents(t *testing.T) {
+	for _, hash := range []bool{false, true} {
+		config, path := newTestConfiguration(t, "key=old\n")
+		config.HashContents.Store(hash)
+		stat, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		writeFile(t, path, "key=new\n")
+		if err := os.Chtimes(path, stat.ModTime(), stat.ModTime()); err != nil {
+			t.Fatal(err)
+		}
+		config.Update()
+		if got, want := config.Get("key"), map[bool]string{false: "old", true: "new"}[hash]; got != want {
+			t.Errorf("key = %q with HashContents %v and a preserved mtime, want %q", got, hash, want)
+		}
+	}
+	logger := &testLogger{}
+	config, path := newTestConfiguration(t, "key=value\n", WithHashContents(), WithLogger(logger), WithLogging(true))
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+	logger.mutex.Lock()
+	logger.lines = nil
+	logger.mutex.Unlock()
+	config.Update()
+	if logger.contains("loaded") {
+		t.Error("a touch without a content change was parsed again")
+	}
+}
+
+func TestPruneRemoved(t *testing.T) {
+	config, path := newTestConfiguration(t, "kept=1\nremoved=1\nlocked=1\n", WithPruneRemoved())
+	config.SetDefault("defaulted", "d")
+	config.SetKeyValue("runtime", "r")
+	config.Lock("locked")
+	var removals []string
+	config.Subscribe(func(event Event) {
+		if event.Kind == Removed {
+			removals = append(removals, event.Key)
+		}
+	})
+	writeFile(t, path, "kept=2\n")
+	config.Reload()
+	if _, found := config.Lookup("removed"); found {
+		t.Error("a key removed from the file survived the reload")
+	} else if len(removals) != 1 || removals[0] != "removed" {
+		t.Errorf("Removed events for %v, want removed", removals)
+	}
+	for key, value := range map[string]string{"kept": "2", "defaulted": "d", "runtime": "r", "locked": "1"} {
+		if got := config.Get(key); got != value {
+			t.Errorf("%s = %q after pruning, want %q", key, got, value)
+		}
+	}
+	unpruned, path := newTestConfiguration(t, "removed=1\n")
+	writeFile(t, path, "other=1\n")
+	unpruned.Reload()
+	if _, found := unpruned.Lookup("removed"); !found {
+		t.Error("a key was pruned without PruneRemoved")
+	}
+}
+
+func TestWaitForKey(t *testing.T) {
+	config, path := newTestConfiguration(t, "")
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		writeFile(t, path, "token=abc\n")
+		config.Reload()
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if got, err := config.WaitForKey(ctx, "token"); err != nil {
+		t.Fatal(err)
+	} else if got != "abc" {
+		t.Errorf("WaitForKey = %q, want abc once the file provides it", got)
+	}
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	if _, err := config.WaitForKey(ctx, "never"); err != context.Canceled {
+		t.Errorf("WaitForKey with a cancelled context = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestHas(t *testing.T) {
+	config, _ := newTestConfiguration(t, "empty=\nname=svc\n")
+	if !config.Has("name") {
+		t.Error("Has(name) = false, want true")
+	}
+	if !config.Has("empty") {
+		t.Error("Has(empty) = false, want true for a key set to the empty string")
+	}
+	if config.Has("missing") {
+		t.Error("Has(missing) = true, want false")
+	}
+}
+
+func TestDelete(t *testing.T) {
+	config, path := newTestConfiguration(t, "name=svc\nport=80\n")
+	config.SetKeyValue("port", "8080")
+	config.Delete("port")
+	if config.Has("port") {
+		t.Error("Delete(port) left the key, or its override, in place")
+	}
+	writeFile(t, path, "name=svc\nport=81\n")
+	config.Reload()
+	if got := config.Get("port"); got != "81" {
+		t.Errorf("port after a reload that changed the file = %q, want 81", got)
+	}
+	config.Clear()
+	if keys := config.Keys(); len(keys) != 0 {
+		t.Errorf("Keys after Clear = %v, want none", keys)
+	}
+}
+
+func TestSetFilenameWaitsForMissingFile(t *testing.T) {
+	config, path := newTestConfiguration(t, "old=1\n")
+	pending := filepath.Join(filepath.Dir(path), "pending.conf")
+	if err := config.SetFilename(pending); err != nil {
+		t.Fatalf("SetFilename to a missing file: %v", err)
+	} else if got := filenameOf(config); got != pending {
+		t.Errorf("filename = %s, want %s", got, pending)
+	}
+	writeFile(t, pending, "new=2\n")
+	config.Update()
+	if got := config.Get("new"); got != "2" {
+		t.Errorf("new = %q once the file appeared, want 2", got)
+	} else if config.Has("old") {
+		t.Error("keys from the previous file survived the switch")
+	}
+}
+
+func TestGetWithTimeoutDerived(t *testing.T) {
+	config, _ := newTestConfiguration(t, "host=a.internal\n")
+	config.RegisterDerived("url", func(c *Configuration) string {
+		return "http://" + c.Get("host")
+	})
+	if got := config.GetWithTimeout("url", time.Second, "fallback"); got != "http://a.internal" {
+		t.Errorf("GetWithTimeout(url) = %q, want the derived value", got)
+	}
+}
+
+func TestForcedReloadDuringUpdate(t *testing.T) {
+	config, path := newTestConfiguration(t, "key=1\n")
+	started, resume := make(chan struct{}), make(chan struct{})
+	config.AddValidator(func(parameters map[string]string) error {
+		if parameters["key"] == "2" {
+			close(started)
+			<-resume
+		}
+		return nil
+	})
+	writeFile(t, path, "key=2\n")
+	stat, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		config.Update()
+	}()
+	<-started
+	writeFile(t, path, "key=3\n")
+	if err := os.Chtimes(path, stat.ModTime(), stat.ModTime()); err != nil {
+		t.Fatal(err)
+	}
+	config.Reload()
+	close(resume)
+	<-done
+	if got := config.Get("key"); got != "3" {
+		t.Errorf("key = %q, want the forced reload requested during Update to have run", got)
+	}
+}
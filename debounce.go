@@ -0,0 +1,37 @@
+package configuration
+
+import (
+	"time"
+)
+
+// SetDebounce holds back a reload until the file has gone unmodified for
+// window, so that a file still being written by an editor, rsync or a
+// templating tool is read once it settles rather than mid-write. Reload
+// and the initial load are never delayed. Zero disables the debounce.
+func (c *Configuration) SetDebounce(window time.Duration) {
+	c.debounce.Store(int64(window))
+}
+
+func WithDebounce(window time.Duration) Option {
+	return func(c *Configuration) {
+		c.SetDebounce(window)
+	}
+}
+
+// settling reports whether a file modified at mtime is still inside the
+// debounce window, scheduling another poll for when the window closes.
+func (c *Configuration) settling(source string, mtime time.Time) bool {
+	window := time.Duration(c.debounce.Load())
+	if window <= 0 || c.lastupdate == 0 {
+		return false
+	}
+	remaining := window - time.Since(mtime)
+	if remaining <= 0 {
+		return false
+	}
+	if c.ShouldLogUpdates.Load() {
+		c.logf("Configuration::update change to %s is settling, waiting %v\n", source, remaining)
+	}
+	time.AfterFunc(remaining, c.wakeUp)
+	return true
+}
@@ -0,0 +1,28 @@
+package configuration
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSetDebounce(t *testing.T) {
+	const window = 200 * time.Millisecond
+	config, path := newTestConfiguration(t, "key=old\n", WithDebounce(window))
+	writeFile(t, path, "key=new\n")
+	config.Update()
+	if got := config.Get("key"); got != "old" {
+		t.Errorf("key = %q right after the write, want old until the file settles", got)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for config.Get("key") != "new" && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := config.Get("key"); got != "new" {
+		t.Errorf("key = %q after the debounce window, want new", got)
+	}
+	writeFile(t, path, "key=forced\n")
+	config.Reload()
+	if got := config.Get("key"); got != "forced" {
+		t.Errorf("key = %q after Reload, want forced without waiting", got)
+	}
+}
@@ -0,0 +1,30 @@
+package configuration
+
+const maxDerivedDepth = 8
+
+// RegisterDerived computes key with fn whenever it is not explicitly set. fn
+// receives a read-only view of the configuration, so derived keys may be
+// built from other derived keys up to a fixed nesting depth.
+func (c *Configuration) RegisterDerived(key string, fn func(c *Configuration) string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.derived == nil {
+		c.derived = make(map[string]func(c *Configuration) string)
+	}
+	c.derived[key] = fn
+}
+
+// view returns a read-only Configuration for a derived key's fn. Its
+// accessors read through to the Configuration the key was registered on,
+// one level deeper, so that recursion between derived keys is bounded.
+func (c *Configuration) view() *Configuration {
+	return &Configuration{root: c.origin(), depth: c.depth + 1, logger: c.logger}
+}
+
+// origin returns the Configuration that holds c's parameters.
+func (c *Configuration) origin() *Configuration {
+	if c.root != nil {
+		return c.root
+	}
+	return c
+}
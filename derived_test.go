@@ -0,0 +1,52 @@
+package configuration
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRegisterDerived(t *testing.T) {
+	config, path := newTestConfiguration(t, "host=a.internal\nport=80\n")
+	config.RegisterDerived("url", func(c *Configuration) string {
+		return "http://" + c.Get("host") + ":" + c.Get("port")
+	})
+	if got := config.Get("url"); got != "http://a.internal:80" {
+		t.Errorf("url = %q, want http://a.internal:80", got)
+	}
+	writeFile(t, path, "host=b.internal\nport=8080\n")
+	config.Update()
+	if got := config.Get("url"); got != "http://b.internal:8080" {
+		t.Errorf("url = %q after reload, want http://b.internal:8080", got)
+	}
+	config.SetKeyValue("url", "http://explicit")
+	if got := config.Get("url"); got != "http://explicit" {
+		t.Errorf("url = %q once set, want http://explicit", got)
+	}
+}
+
+func TestRegisterDerivedRecursion(t *testing.T) {
+	config, _ := newTestConfiguration(t, "")
+	config.RegisterDerived("a", func(c *Configuration) string { return c.Get("b") })
+	config.RegisterDerived("b", func(c *Configuration) string { return c.Get("a") })
+	if got := config.Get("a"); got != "" {
+		t.Errorf("a = %q for a derived cycle, want empty", got)
+	}
+}
+
+func TestRegisterDerivedReadsThroughView(t *testing.T) {
+	config, path := newTestConfiguration(t, "route.a=1\nroute.b=2\nhost=a.internal\n")
+	config.RegisterDerived("routes", func(c *Configuration) string {
+		return strings.Join(c.KeysWithPrefix("route."), ",")
+	})
+	config.RegisterDerived("host_from", func(c *Configuration) string {
+		file, _, _ := c.Origin("host")
+		return file + " " + c.GetWithTimeout("host", time.Second, "fallback")
+	})
+	if got := config.Get("routes"); got != "route.a,route.b" {
+		t.Errorf("routes = %q, want the keys seen by the view", got)
+	}
+	if got, want := config.Get("host_from"), path+" a.internal"; got != want {
+		t.Errorf("host_from = %q, want %q", got, want)
+	}
+}
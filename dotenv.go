@@ -0,0 +1,95 @@
+package configuration
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// parseDotenv reads docker-compose style .env files: an optional export
+// prefix, '=' as the only delimiter, single quotes taken literally, double
+// quotes with backslash escapes and # comments outside of quotes.
+func (c *Configuration) parseDotenv(caller, filename string, r io.Reader) (parsed, error) {
+	result := parsed{entries: make([]entry, 0)}
+	seen := make(map[string]struct{})
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if len(text) == 0 {
+			continue
+		} else if strings.HasPrefix(text, "#") {
+			result.summary.Comments++
+			continue
+		}
+		text = strings.TrimPrefix(text, "export ")
+		key, value, found := strings.Cut(text, "=")
+		if key = strings.TrimSpace(key); !found || len(key) == 0 {
+			err := errors.New("expected KEY=value")
+			result.errors = append(result.errors, fmt.Errorf("line %d: %w", line, err))
+			if c.ShouldLogUpdates.Load() {
+				c.logf("Configuration::%s error parsing %s: %v\n", caller, escape(scanner.Text()), err)
+			}
+			continue
+		}
+		value, err := dotenvValue(strings.TrimSpace(value))
+		if err != nil {
+			result.errors = append(result.errors, fmt.Errorf("line %d: %w", line, err))
+			if c.ShouldLogUpdates.Load() {
+				c.logf("Configuration::%s error parsing %s: %v\n", caller, escape(scanner.Text()), err)
+			}
+			continue
+		}
+		if _, found := seen[key]; found {
+			result.summary.Duplicates++
+		} else {
+			seen[key] = struct{}{}
+		}
+		result.entries = append(result.entries, entry{key: key, value: value, file: filename, line: line})
+	}
+	result.summary.Keys = len(seen)
+	result.summary.Errors = len(result.errors)
+	return result, scanner.Err()
+}
+
+func dotenvValue(value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, "'"):
+		if end := strings.IndexByte(value[1:], '\''); end == -1 {
+			return "", errors.New("unterminated single quote")
+		} else {
+			return value[1 : end+1], nil
+		}
+	case strings.HasPrefix(value, `"`):
+		var builder strings.Builder
+		for i := 1; i < len(value); i++ {
+			switch value[i] {
+			case '"':
+				return builder.String(), nil
+			case '\\':
+				if i++; i == len(value) {
+					return "", errors.New("unterminated double quote")
+				}
+				switch value[i] {
+				case 'n':
+					builder.WriteByte('\n')
+				case 't':
+					builder.WriteByte('\t')
+				case 'r':
+					builder.WriteByte('\r')
+				default:
+					builder.WriteByte(value[i])
+				}
+			default:
+				builder.WriteByte(value[i])
+			}
+		}
+		return "", errors.New("unterminated double quote")
+	default:
+		if i := strings.Index(value, " #"); i != -1 {
+			value = value[:i]
+		}
+		return strings.TrimSpace(value), nil
+	}
+}
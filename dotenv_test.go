@@ -0,0 +1,40 @@
+package configuration
+
+import (
+	"testing"
+)
+
+func TestDotenvFormat(t *testing.T) {
+	config, path := newTestConfiguration(t, "", WithErrorThreshold(-1))
+	config.SetFormat(FormatDotenv)
+	writeFile(t, path, `# comment
+export HOST=localhost
+PORT = 8080
+URL=http://example.com:80/path # trailing comment
+LITERAL='a \n $b # c'
+ESCAPED="line1\nline2\t\"quoted\""
+EMPTY=
+BROKEN="unterminated
+not a pair
+`)
+	config.Reload()
+	want := map[string]string{
+		"HOST":    "localhost",
+		"PORT":    "8080",
+		"URL":     "http://example.com:80/path",
+		"LITERAL": `a \n $b # c`,
+		"ESCAPED": "line1\nline2\t\"quoted\"",
+		"EMPTY":   "",
+	}
+	for key, value := range want {
+		if got, found := config.Lookup(key); !found || got != value {
+			t.Errorf("%s = %q, %v, want %q", key, got, found, value)
+		}
+	}
+	if _, found := config.Lookup("BROKEN"); found {
+		t.Error("an unterminated double quote was stored")
+	}
+	if summary := config.LastParseSummary(); summary.Comments != 1 || summary.Errors != 2 || summary.Keys != len(want) {
+		t.Errorf("summary = %+v, want %d keys, 1 comment and 2 errors", summary, len(want))
+	}
+}
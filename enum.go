@@ -0,0 +1,43 @@
+package configuration
+
+import (
+	"encoding/binary"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+var (
+	byteOrders = map[string]binary.ByteOrder{
+		"big":    binary.BigEndian,
+		"little": binary.LittleEndian,
+	}
+	slogLevels = map[string]slog.Level{
+		"debug": slog.LevelDebug,
+		"info":  slog.LevelInfo,
+		"warn":  slog.LevelWarn,
+		"error": slog.LevelError,
+	}
+)
+
+// GetEnum maps the value of key onto one of values, matching names
+// case-insensitively. The names in values must be lowercase.
+func GetEnum[T any](c *Configuration, key string, values map[string]T) (T, error) {
+	var zero T
+	value, err := c.lookup(key)
+	if err != nil {
+		return zero, err
+	} else if t, found := values[strings.ToLower(strings.TrimSpace(value))]; !found {
+		return zero, fmt.Errorf("key '%s': unrecognized value '%s'", key, value)
+	} else {
+		return t, nil
+	}
+}
+
+func (c *Configuration) GetByteOrder(key string) (binary.ByteOrder, error) {
+	return GetEnum(c, key, byteOrders)
+}
+
+func (c *Configuration) GetSlogLevel(key string) (slog.Level, error) {
+	return GetEnum(c, key, slogLevels)
+}
@@ -0,0 +1,38 @@
+package configuration
+
+import (
+	"encoding/binary"
+	"errors"
+	"log/slog"
+	"testing"
+)
+
+func TestGetByteOrder(t *testing.T) {
+	config, _ := newTestConfiguration(t, "network=big\nhost=LITTLE\nodd=middle\n")
+	if order, err := config.GetByteOrder("network"); err != nil || order != binary.BigEndian {
+		t.Errorf("GetByteOrder(network) = %v, %v, want %v", order, err, binary.BigEndian)
+	}
+	if order, err := config.GetByteOrder("host"); err != nil || order != binary.LittleEndian {
+		t.Errorf("GetByteOrder(host) = %v, %v, want %v", order, err, binary.LittleEndian)
+	}
+	if _, err := config.GetByteOrder("odd"); err == nil {
+		t.Error("GetByteOrder accepted middle")
+	} else if _, err := config.GetByteOrder("missing"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("GetByteOrder of a missing key = %v, want %v", err, ErrKeyNotFound)
+	}
+}
+
+func TestGetSlogLevel(t *testing.T) {
+	config, _ := newTestConfiguration(t, "debug=debug\ninfo=info\nwarn=Warn\nerror=ERROR\nbad=verbose\n")
+	want := map[string]slog.Level{"debug": slog.LevelDebug, "info": slog.LevelInfo, "warn": slog.LevelWarn, "error": slog.LevelError}
+	for key, level := range want {
+		if got, err := config.GetSlogLevel(key); err != nil || got != level {
+			t.Errorf("GetSlogLevel(%q) = %v, %v, want %v", key, got, err, level)
+		}
+	}
+	if _, err := config.GetSlogLevel("bad"); err == nil {
+		t.Error("GetSlogLevel accepted verbose")
+	} else if _, err := config.GetSlogLevel("missing"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("GetSlogLevel of a missing key = %v, want %v", err, ErrKeyNotFound)
+	}
+}
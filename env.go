@@ -0,0 +1,112 @@
+package configuration
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// SetEnvOverlay lets environment variables override values loaded from
+// files: with prefix "MYAPP", key database.url is overridden by
+// MYAPP_DATABASE_URL. The overlay applies from the next reload.
+func (c *Configuration) SetEnvOverlay(prefix string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.envOverlay, c.envPrefix = true, prefix
+	c.lastupdate = 0
+}
+
+func EnvName(prefix, key string) string {
+	name := strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(key))
+	if len(prefix) == 0 {
+		return name
+	} else if strings.HasSuffix(prefix, "_") {
+		return prefix + name
+	}
+	return prefix + "_" + name
+}
+
+func (c *Configuration) overlay(entries []entry) []entry {
+	if !c.envOverlay {
+		return entries
+	}
+	for i, e := range entries {
+		if value, found := os.LookupEnv(EnvName(c.envPrefix, e.key)); found {
+			entries[i].value, entries[i].file, entries[i].line = value, "$"+EnvName(c.envPrefix, e.key), 0
+		}
+	}
+	return entries
+}
+
+// expand replaces ${NAME} references in value using lookup and turns $$ into
+// a literal $. Unknown names expand to the empty string; a $ followed by
+// anything else is left alone.
+func expand(value string, lookup func(name string) string) string {
+	if !strings.Contains(value, "$") {
+		return value
+	}
+	var builder strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '$' || i+1 == len(value) {
+			builder.WriteByte(value[i])
+		} else if value[i+1] == '$' {
+			builder.WriteByte('$')
+			i++
+		} else if end := strings.IndexByte(value[i:], '}'); value[i+1] == '{' && end != -1 {
+			builder.WriteString(lookup(value[i+2 : i+end]))
+			i += end
+		} else {
+			builder.WriteByte(value[i])
+		}
+	}
+	return builder.String()
+}
+
+// interpolate expands ${NAME} references in the values of entries. With
+// InterpolateKeys, NAME is first looked up among the other keys, resolving
+// their own references in turn; with ExpandEnv it may name an environment
+// variable. A chain of keys that refers back to itself is an error.
+func (c *Configuration) interpolate(entries []entry) ([]entry, error) {
+	keys, env := c.InterpolateKeys.Load(), c.ExpandEnv.Load()
+	if !keys && !env {
+		return entries, nil
+	}
+	raw := make(map[string]string, len(c.parameters)+len(entries))
+	for key, value := range c.parameters {
+		raw[key] = value
+	}
+	for _, e := range entries {
+		raw[e.key] = e.value
+	}
+	resolved := make(map[string]string)
+	resolving := make(map[string]bool)
+	var err error
+	var resolve func(key string) string
+	lookup := func(name string) string {
+		if _, found := raw[name]; keys && found {
+			return resolve(name)
+		} else if env {
+			return os.Getenv(name)
+		}
+		return ""
+	}
+	resolve = func(key string) string {
+		if value, found := resolved[key]; found {
+			return value
+		} else if resolving[key] {
+			if err == nil {
+				err = fmt.Errorf("interpolation cycle through key '%s'", key)
+			}
+			return ""
+		}
+		resolving[key] = true
+		value := expand(raw[key], lookup)
+		resolving[key] = false
+		resolved[key] = value
+		return value
+	}
+	for i := range entries {
+		entries[i].value = resolve(entries[i].key)
+	}
+	return entries, err
+}
@@ -0,0 +1,122 @@
+package configuration
+
+import (
+	"testing"
+)
+
+func TestEnvName(t *testing.T) {
+	tests := []struct {
+		prefix, key, want string
+	}{
+		{"MYAPP", "database.url", "MYAPP_DATABASE_URL"},
+		{"MYAPP_", "log-level", "MYAPP_LOG_LEVEL"},
+		{"", "port", "PORT"},
+	}
+	for _, test := range tests {
+		if got := EnvName(test.prefix, test.key); got != test.want {
+			t.Errorf("EnvName(%q, %q) = %q, want %q", test.prefix, test.key, got, test.want)
+		}
+	}
+}
+
+func TestEnvOverlay(t *testing.T) {
+	t.Setenv("OVERLAYTEST_DATABASE_URL", "postgres://env")
+	config, _ := newTestConfiguration(t, "database.url=postgres://file\nport=8080\n")
+	if got := config.Get("database.url"); got != "postgres://file" {
+		t.Errorf("database.url = %q before SetEnvOverlay, want the file value", got)
+	}
+	config.SetEnvOverlay("OVERLAYTEST")
+	config.Update()
+	if got := config.Get("database.url"); got != "postgres://env" {
+		t.Errorf("database.url = %q with the overlay, want the environment value", got)
+	} else if got := config.Get("port"); got != "8080" {
+		t.Errorf("port = %q, want the file value without a matching variable", got)
+	}
+	if file, line, _ := config.Origin("database.url"); file != "$OVERLAYTEST_DATABASE_URL" || line != 0 {
+		t.Errorf("Origin(database.url) = %s:%d, want the environment variable", file, line)
+	}
+}
+
+func TestExpand(t *testing.T) {
+	lookup := func(name string) string {
+		return map[string]string{"HOME": "/home/app", "USER": "app"}[name]
+	}
+	tests := []struct {
+		value, want string
+	}{
+		{"${HOME}/data", "/home/app/data"},
+		{"${USER}@${HOME}", "app@/home/app"},
+		{"${UNKNOWN}x", "x"},
+		{"cost $$5", "cost $5"},
+		{"$HOME", "$HOME"},
+		{"${unterminated", "${unterminated"},
+		{"trailing $", "trailing $"},
+	}
+	for _, test := range tests {
+		if got := expand(test.value, lookup); got != test.want {
+			t.Errorf("expand(%q) = %q, want %q", test.value, got, test.want)
+		}
+	}
+}
+
+func TestExpandEnv(t *testing.T) {
+	t.Setenv("EXPANDTEST_DIR", "/srv")
+	config, path := newTestConfiguration(t, "data=${EXPANDTEST_DIR}/data\n")
+	if got := config.Get("data"); got != "${EXPANDTEST_DIR}/data" {
+		t.Errorf("data = %q without ExpandEnv, want the raw value", got)
+	}
+	config.ExpandEnv.Store(true)
+	writeFile(t, path, "data=${EXPANDTEST_DIR}/data\nprice=$$5\n")
+	config.Update()
+	if got := config.Get("data"); got != "/srv/data" {
+		t.Errorf("data = %q with ExpandEnv, want /srv/data", got)
+	} else if got := config.Get("price"); got != "$5" {
+		t.Errorf("price = %q with ExpandEnv, want $5", got)
+	}
+}
+
+func TestInterpolateKeys(t *testing.T) {
+	t.Setenv("INTERPOLATETEST_ROOT", "/srv")
+	config, path := newTestConfiguration(t, "")
+	config.InterpolateKeys.Store(true)
+	writeFile(t, path, "log_dir=${base_dir}/logs\nbase_dir=${app_dir}/base\napp_dir=/opt/app\nhome=${INTERPOLATETEST_ROOT}\n")
+	config.Update()
+	if got := config.Get("log_dir"); got != "/opt/app/base/logs" {
+		t.Errorf("log_dir = %q, want /opt/app/base/logs", got)
+	} else if got := config.Get("home"); got != "" {
+		t.Errorf("home = %q without ExpandEnv, want an empty expansion", got)
+	}
+	config.ExpandEnv.Store(true)
+	writeFile(t, path, "log_dir=${base_dir}/logs\nbase_dir=${app_dir}/base\napp_dir=/opt/app\nhome=${INTERPOLATETEST_ROOT}\n")
+	config.Update()
+	if got := config.Get("home"); got != "/srv" {
+		t.Errorf("home = %q with ExpandEnv, want /srv", got)
+	}
+}
+
+func TestInterpolateKeysCycle(t *testing.T) {
+	config, path := newTestConfiguration(t, "a=kept\n")
+	config.InterpolateKeys.Store(true)
+	writeFile(t, path, "a=${b}\nb=${c}\nc=${a}\n")
+	config.Update()
+	if got := config.Get("a"); got != "kept" {
+		t.Errorf("a = %q after a cyclic file, want the previous value kept", got)
+	} else if _, found := config.Lookup("b"); found {
+		t.Error("a cyclic file was partially applied")
+	}
+	writeFile(t, path, "a=${a}\n")
+	config.Update()
+	if got := config.Get("a"); got != "kept" {
+		t.Errorf("a = %q after a self reference, want the previous value kept", got)
+	}
+}
+
+func TestInterpolateKeysSeesOverlay(t *testing.T) {
+	t.Setenv("SEESOVERLAY_APP_DIR", "/env/app")
+	config, _ := newTestConfiguration(t, "app_dir=/opt/app\nlog_dir=${app_dir}/logs\n", WithEnvOverlay("SEESOVERLAY"))
+	config.InterpolateKeys.Store(true)
+	config.Reload()
+	if got := config.Get("log_dir"); got != "/env/app/logs" {
+		t.Errorf("log_dir = %q, want it built from the overlaid app_dir", got)
+	}
+}
@@ -0,0 +1,202 @@
+package configuration
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+type EventKind int
+
+const (
+	Added EventKind = iota
+	Updated
+	Removed
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case Added:
+		return "added"
+	case Updated:
+		return "updated"
+	case Removed:
+		return "removed"
+	default:
+		return fmt.Sprintf("EventKind(%d)", int(k))
+	}
+}
+
+func (k EventKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+func (k *EventKind) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "added":
+		*k = Added
+	case "updated":
+		*k = Updated
+	case "removed":
+		*k = Removed
+	default:
+		return fmt.Errorf("unknown event kind '%s'", text)
+	}
+	return nil
+}
+
+type Event struct {
+	Time   time.Time `json:"time"`
+	Kind   EventKind `json:"kind"`
+	Key    string    `json:"key"`
+	Old    string    `json:"old,omitempty"`
+	New    string    `json:"new,omitempty"`
+	Source string    `json:"source"`
+}
+
+type subscriber struct {
+	id     int
+	fn     func([]Event)
+	mutex  sync.Mutex
+	busy   bool
+	queued [][]Event
+}
+
+// deliver queues events for s and, unless another caller is already
+// delivering to s, hands every queued batch to fn in order. A batch caused
+// by fn itself is delivered once fn returns.
+func (s *subscriber) deliver(events []Event) {
+	s.mutex.Lock()
+	s.queued = append(s.queued, events)
+	if s.busy {
+		s.mutex.Unlock()
+		return
+	}
+	s.busy = true
+	s.drain()
+}
+
+// drain delivers the queued batches. The caller holds s.mutex and has
+// marked s busy.
+func (s *subscriber) drain() {
+	for len(s.queued) > 0 {
+		batch := s.queued[0]
+		s.queued = s.queued[1:]
+		s.mutex.Unlock()
+		s.fn(batch)
+		s.mutex.Lock()
+	}
+	s.busy = false
+	s.mutex.Unlock()
+}
+
+// Subscribe calls fn for every change applied to the configuration, after
+// the change is visible to readers. The returned function unsubscribes.
+func (c *Configuration) Subscribe(fn func(Event)) (unsubscribe func()) {
+	return c.subscribe(func(events []Event) {
+		for _, event := range events {
+			fn(event)
+		}
+	})
+}
+
+func (c *Configuration) subscribe(fn func([]Event)) (unsubscribe func()) {
+	c.mutex.Lock()
+	c.nextSubscriber++
+	id := c.nextSubscriber
+	s := &subscriber{id: id, fn: fn, busy: true}
+	c.subscribers = append(c.subscribers, s)
+	if c.ReplayOnSubscribe.Load() {
+		now := c.clock()
+		replay := make([]Event, 0, len(c.parameters))
+		for _, key := range sortedKeys(c.parameters) {
+			replay = append(replay, Event{Time: now, Kind: Added, Key: key, New: c.parameters[key], Source: "replay"})
+		}
+		if len(replay) > 0 {
+			s.queued = append(s.queued, replay)
+		}
+	}
+	c.mutex.Unlock()
+	// s was registered busy, so live events queue behind the replay.
+	s.mutex.Lock()
+	s.drain()
+	return func() {
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+		for i, s := range c.subscribers {
+			if s.id == id {
+				c.subscribers = append(c.subscribers[:i:i], c.subscribers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// SetEventLog appends every subsequent Event to filename as a JSON line.
+// An empty filename stops logging.
+func (c *Configuration) SetEventLog(filename string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.eventlog != nil {
+		c.eventlog.Close()
+		c.eventlog = nil
+	}
+	if len(filename) == 0 {
+		return nil
+	}
+	f, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	c.eventlog = f
+	return nil
+}
+
+func (c *Configuration) record(event Event) {
+	event.Time = c.clock()
+	c.pending = append(c.pending, event)
+}
+
+func (c *Configuration) release() {
+	events := c.pending
+	c.pending = nil
+	if len(events) > 0 {
+		c.version.Add(1)
+		c.revise(events)
+	}
+	if c.eventlog != nil {
+		for _, event := range events {
+			if line, err := json.Marshal(event); err != nil {
+				c.logf("Configuration::release error encoding event: %v\n", err)
+			} else if _, err := c.eventlog.Write(append(line, '\n')); err != nil {
+				c.logf("Configuration::release error writing %s: %v\n", c.eventlog.Name(), err)
+			}
+		}
+	}
+	subscribers := c.subscribers
+	after, changeSetHooks, changes := c.takeReloaded()
+	c.mutex.Unlock()
+	for _, set := range changes {
+		for _, hook := range after {
+			hook(set.Keys())
+		}
+		for _, hook := range changeSetHooks {
+			hook(set)
+		}
+	}
+	if len(events) == 0 {
+		return
+	}
+	for _, s := range subscribers {
+		s.deliver(events)
+	}
+}
+
+// Version increases by one with every reload, SetKeyValue or Transaction
+// that changes the parameters, so callers caching values derived from them
+// can cheaply tell when the cache is stale.
+func (c *Configuration) Version() int64 {
+	return c.version.Load()
+}
@@ -0,0 +1,155 @@
+package configuration
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestEventLogPersists(t *testing.T) {
+	log := filepath.Join(t.TempDir(), "events.jsonl")
+	config, _ := newTestConfiguration(t, "")
+	if err := config.SetEventLog(log); err != nil {
+		t.Fatal(err)
+	}
+	config.SetKeyValue("a", "1")
+	config.SetKeyValue("a", "2")
+	tx := config.Begin()
+	tx.Delete("a")
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	config.Close()
+
+	restarted, _ := newTestConfiguration(t, "")
+	if err := restarted.SetEventLog(log); err != nil {
+		t.Fatal(err)
+	}
+	restarted.SetKeyValue("b", "3")
+	restarted.Close()
+
+	f, err := os.Open(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	events := make([]Event, 0)
+	for scanner := bufio.NewScanner(f); scanner.Scan(); {
+		var event Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatal(err)
+		}
+		events = append(events, event)
+	}
+	want := []Event{
+		{Kind: Added, Key: "a", New: "1"},
+		{Kind: Updated, Key: "a", Old: "1", New: "2"},
+		{Kind: Removed, Key: "a", Old: "2"},
+		{Kind: Added, Key: "b", New: "3"},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("event log has %d events, want %d: %v", len(events), len(want), events)
+	}
+	for i, event := range events {
+		if event.Kind != want[i].Kind || event.Key != want[i].Key || event.Old != want[i].Old || event.New != want[i].New {
+			t.Errorf("event %d = %+v, want %+v", i, event, want[i])
+		}
+	}
+}
+
+func TestReplayOnSubscribe(t *testing.T) {
+	config, _ := newTestConfiguration(t, "a=1\nb=2\n")
+	config.ReplayOnSubscribe.Store(true)
+	var (
+		mutex  sync.Mutex
+		events []Event
+	)
+	config.Subscribe(func(event Event) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		events = append(events, event)
+	})
+	config.SetKeyValue("c", "3")
+	mutex.Lock()
+	defer mutex.Unlock()
+	if len(events) != 3 {
+		t.Fatalf("subscriber got %+v, want the replay of a and b and then c", events)
+	}
+	if events[0].Key != "a" || events[1].Key != "b" || events[0].Kind != Added || events[1].New != "2" || events[0].Source != "replay" {
+		t.Errorf("replay = %+v, want Added events for a and b", events[:2])
+	}
+	if events[2].Key != "c" || events[2].Source == "replay" {
+		t.Errorf("live event = %+v, want c", events[2])
+	}
+	unreplayed, _ := newTestConfiguration(t, "a=1\n")
+	called := false
+	unreplayed.Subscribe(func(Event) { called = true })
+	if called {
+		t.Error("Subscribe replayed without ReplayOnSubscribe")
+	}
+}
+
+func TestVersion(t *testing.T) {
+	config, path := newTestConfiguration(t, "a=1\nb=1\n")
+	start := config.Version()
+	writeFile(t, path, "a=2\nb=2\n")
+	config.Reload()
+	if got := config.Version(); got != start+1 {
+		t.Errorf("Version = %d after a reload changing two keys, want %d", got, start+1)
+	}
+	config.Reload()
+	config.SetKeyValue("a", "2")
+	if got := config.Version(); got != start+1 {
+		t.Errorf("Version = %d after changes that changed nothing, want %d", got, start+1)
+	}
+	config.SetKeyValue("a", "3")
+	tx := config.Begin()
+	tx.Set("c", "1")
+	tx.Delete("b")
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if got := config.Version(); got != start+3 {
+		t.Errorf("Version = %d after SetKeyValue and a Transaction, want %d", got, start+3)
+	}
+}
+
+func TestReplayPrecedesLiveEvents(t *testing.T) {
+	config, _ := newTestConfiguration(t, "a=1\nb=2\n")
+	config.ReplayOnSubscribe.Store(true)
+	var (
+		mutex   sync.Mutex
+		batches [][]Event
+	)
+	inReplay, resume := make(chan struct{}), make(chan struct{})
+	go func() {
+		<-inReplay
+		config.SetKeyValue("c", "3")
+		close(resume)
+	}()
+	config.subscribe(func(events []Event) {
+		mutex.Lock()
+		first := len(batches) == 0
+		batches = append(batches, events)
+		mutex.Unlock()
+		if first {
+			close(inReplay)
+			<-resume
+		}
+	})
+	mutex.Lock()
+	defer mutex.Unlock()
+	if len(batches) != 2 {
+		t.Fatalf("subscriber got %d batches, want the replay and one live change", len(batches))
+	}
+	replay := batches[0]
+	if len(replay) != 2 || replay[0].Key != "a" || replay[1].Key != "b" || replay[0].Kind != Added || replay[1].New != "2" {
+		t.Errorf("replay = %+v, want Added events for a and b", replay)
+	}
+	if live := batches[1]; len(live) != 1 || live[0].Key != "c" {
+		t.Errorf("live batch = %+v, want c", live)
+	}
+}
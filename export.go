@@ -0,0 +1,163 @@
+package configuration
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrUnwritableKey = errors.New("key cannot be written on one line")
+)
+
+// WriteDiff writes the parameters that differ from baseline as key=value
+// lines, followed by a comment for each key that has been removed. A value
+// the parser would not read back verbatim is double-quoted and escaped; a
+// key containing a line break is rejected with ErrUnwritableKey.
+func (c *Configuration) WriteDiff(w io.Writer, baseline map[string]string) error {
+	c = c.origin()
+	c.mutex.RLock()
+	changed := make(map[string]string)
+	for key, value := range c.parameters {
+		if stored, found := baseline[key]; !found || stored != value {
+			changed[key] = value
+		}
+	}
+	removed := make([]string, 0)
+	for key := range baseline {
+		if _, found := c.parameters[key]; !found {
+			removed = append(removed, key)
+		}
+	}
+	c.mutex.RUnlock()
+	for _, key := range append(sortedKeys(changed), removed...) {
+		if strings.ContainsAny(key, "\r\n") {
+			return fmt.Errorf("%w: '%s'", ErrUnwritableKey, escape(key))
+		}
+	}
+	writer := bufio.NewWriter(w)
+	for _, key := range sortedKeys(changed) {
+		if _, err := fmt.Fprintf(writer, "%s=%s\n", key, quote(changed[key])); err != nil {
+			return err
+		}
+	}
+	sort.Strings(removed)
+	for _, key := range removed {
+		if _, err := fmt.Fprintf(writer, "# removed: %s\n", key); err != nil {
+			return err
+		}
+	}
+	return writer.Flush()
+}
+
+// quote returns value as it must be written for unquote to give it back:
+// unchanged when it would be read verbatim, otherwise double-quoted with
+// its quotes, backslashes and line breaks escaped.
+func quote(value string) string {
+	if value == strings.TrimSpace(value) && !strings.ContainsAny(value, "\"'\\#\n\r\t") && !strings.HasPrefix(value, "<<") {
+		return value
+	}
+	var builder strings.Builder
+	builder.WriteByte('"')
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case '\n':
+			builder.WriteString(`\n`)
+		case '\t':
+			builder.WriteString(`\t`)
+		case '\r':
+			builder.WriteString(`\r`)
+		case '"', '\\':
+			builder.WriteByte('\\')
+			builder.WriteByte(value[i])
+		default:
+			builder.WriteByte(value[i])
+		}
+	}
+	builder.WriteByte('"')
+	return builder.String()
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+type Kind int
+
+const (
+	KindString Kind = iota
+	KindInt
+	KindBool
+	KindFloat
+	KindDuration
+)
+
+func (c *Configuration) TypedMap(schema map[string]Kind) (map[string]any, error) {
+	c = c.origin()
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	results := make(map[string]any, len(c.parameters))
+	errs := make([]error, 0)
+	for key, value := range c.parameters {
+		if typed, err := parseKind(schema[key], value); err != nil {
+			errs = append(errs, fmt.Errorf("key '%s': %w", key, err))
+		} else {
+			results[key] = typed
+		}
+	}
+	return results, errors.Join(errs...)
+}
+
+func parseKind(kind Kind, value string) (any, error) {
+	switch kind {
+	case KindInt:
+		return strconv.Atoi(strings.TrimSpace(value))
+	case KindBool:
+		return strconv.ParseBool(strings.TrimSpace(value))
+	case KindFloat:
+		return strconv.ParseFloat(strings.TrimSpace(value), 64)
+	case KindDuration:
+		return time.ParseDuration(strings.TrimSpace(value))
+	default:
+		return value, nil
+	}
+}
+
+func (c *Configuration) Redacted(isSensitive func(key string) bool) map[string]string {
+	c = c.origin()
+	c.mutex.RLock()
+	results := make(map[string]string, len(c.parameters))
+	for key, value := range c.parameters {
+		results[key] = value
+	}
+	c.mutex.RUnlock()
+	for key := range results {
+		if isSensitive(key) {
+			results[key] = "****"
+		}
+	}
+	return results
+}
+
+func (c *Configuration) ChangedSince(t time.Time) map[string]string {
+	c = c.origin()
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	results := make(map[string]string)
+	for key, modified := range c.modified {
+		if !modified.Before(t) {
+			results[key] = c.parameters[key]
+		}
+	}
+	return results
+}
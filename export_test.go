@@ -0,0 +1,107 @@
+package configuration
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteDiff(t *testing.T) {
+	config, _ := newTestConfiguration(t, "same=1\nchanged=new\nadded=yes\n")
+	baseline := map[string]string{"same": "1", "changed": "old", "gone": "x"}
+	builder := new(strings.Builder)
+	if err := config.WriteDiff(builder, baseline); err != nil {
+		t.Fatal(err)
+	}
+	want := "added=yes\nchanged=new\n# removed: gone\n"
+	if got := builder.String(); got != want {
+		t.Errorf("WriteDiff wrote %q, want %q", got, want)
+	}
+}
+
+func TestTypedMap(t *testing.T) {
+	config, _ := newTestConfiguration(t, "workers=4\ndebug=true\nratio=0.5\ntimeout=3s\nname=svc\nretries=many\n")
+	schema := map[string]Kind{
+		"workers": KindInt,
+		"debug":   KindBool,
+		"ratio":   KindFloat,
+		"timeout": KindDuration,
+		"retries": KindInt,
+	}
+	typed, err := config.TypedMap(schema)
+	if err == nil || !strings.Contains(err.Error(), "retries") {
+		t.Errorf("TypedMap error = %v, want one naming retries", err)
+	}
+	want := map[string]any{"workers": 4, "debug": true, "ratio": 0.5, "timeout": 3 * time.Second, "name": "svc"}
+	for key, value := range want {
+		if typed[key] != value {
+			t.Errorf("TypedMap[%q] = %#v, want %#v", key, typed[key], value)
+		}
+	}
+	if _, found := typed["retries"]; found {
+		t.Error("TypedMap included a value that failed to parse")
+	}
+}
+
+func TestRedacted(t *testing.T) {
+	config, _ := newTestConfiguration(t, "db_password=hunter2\nadmin.password=secret\nuser=alice\n")
+	redacted := config.Redacted(func(key string) bool { return strings.Contains(key, "password") })
+	want := map[string]string{"db_password": "****", "admin.password": "****", "user": "alice"}
+	for key, value := range want {
+		if redacted[key] != value {
+			t.Errorf("Redacted[%q] = %q, want %q", key, redacted[key], value)
+		}
+	}
+	if got := config.Get("db_password"); got != "hunter2" {
+		t.Errorf("Redacted changed the stored value to %q", got)
+	}
+}
+
+func TestChangedSince(t *testing.T) {
+	config, _ := newTestConfiguration(t, "a=1\nb=1\nc=1\n")
+	checkpoint := time.Now().Add(time.Hour)
+	config.setClock(func() time.Time { return checkpoint })
+	config.SetKeyValue("a", "2")
+	config.SetKeyValue("c", "2")
+	changed := config.ChangedSince(checkpoint)
+	if len(changed) != 2 || changed["a"] != "2" || changed["c"] != "2" {
+		t.Errorf("ChangedSince = %v, want a and c", changed)
+	}
+	if changed := config.ChangedSince(checkpoint.Add(time.Second)); len(changed) != 0 {
+		t.Errorf("ChangedSince after the last change = %v, want none", changed)
+	}
+}
+
+func TestWriteDiffRoundTrip(t *testing.T) {
+	values := map[string]string{
+		"plain":     "value",
+		"padded":    "  padded  ",
+		"quoted":    `say "hi"`,
+		"single":    "'literal'",
+		"backslash": `C:\path\n`,
+		"multiline": "first\nsecond",
+		"tab":       "a\tb",
+		"comment":   "a # not a comment",
+		"heredoc":   "<<END",
+		"trailing":  `ends with \`,
+	}
+	config, _ := newTestConfiguration(t, "")
+	for key, value := range values {
+		config.SetKeyValue(key, value)
+	}
+	builder := new(strings.Builder)
+	if err := config.WriteDiff(builder, nil); err != nil {
+		t.Fatal(err)
+	}
+	reread, _ := newTestConfiguration(t, builder.String())
+	for key, value := range values {
+		if got := reread.Get(key); got != value {
+			t.Errorf("%s = %q after a round trip through %q, want %q", key, got, builder.String(), value)
+		}
+	}
+	config.SetKeyValue("bad\nkey", "x")
+	if err := config.WriteDiff(new(strings.Builder), nil); !errors.Is(err, ErrUnwritableKey) {
+		t.Errorf("WriteDiff of a key with a newline = %v, want %v", err, ErrUnwritableKey)
+	}
+}
@@ -0,0 +1,240 @@
+package configuration
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+type Format string
+
+const (
+	FormatKeyValue Format = ""
+	FormatJSON     Format = "json"
+	FormatYAML     Format = "yaml"
+	FormatTOML     Format = "toml"
+	FormatDotenv   Format = "env"
+)
+
+// Parser decodes a configuration document into flat key/value pairs.
+type Parser interface {
+	Parse(r io.Reader) (map[string]string, error)
+}
+
+type ParserFunc func(r io.Reader) (map[string]string, error)
+
+func (f ParserFunc) Parse(r io.Reader) (map[string]string, error) {
+	return f(r)
+}
+
+// builtin is the registration for a format the package parses itself, which
+// keeps line numbers and honors per-Configuration parsing options.
+type builtin Format
+
+func (b builtin) Parse(r io.Reader) (map[string]string, error) {
+	result, err := (&Configuration{}).decodeBuiltin("Parse", "", Format(b), r)
+	if err != nil {
+		return nil, err
+	}
+	results := make(map[string]string, len(result.entries))
+	for _, e := range result.entries {
+		results[e.key] = e.value
+	}
+	return results, nil
+}
+
+var (
+	formats = map[Format]Parser{
+		FormatKeyValue: builtin(FormatKeyValue),
+		FormatJSON:     builtin(FormatJSON),
+		FormatYAML:     builtin(FormatYAML),
+		"yml":          builtin(FormatYAML),
+		FormatTOML:     builtin(FormatTOML),
+		FormatDotenv:   builtin(FormatDotenv),
+	}
+	formatsMutex sync.RWMutex
+)
+
+// RegisterFormat makes p the parser for files with extension ext (with or
+// without the leading dot), replacing any earlier registration. Registering
+// the empty extension replaces the default key=value parser.
+func RegisterFormat(ext string, p Parser) {
+	formatsMutex.Lock()
+	defer formatsMutex.Unlock()
+	formats[formatOf(ext)] = p
+}
+
+func formatOf(ext string) Format {
+	return Format(strings.ToLower(strings.TrimPrefix(ext, ".")))
+}
+
+// SetFormat overrides the format otherwise detected from the file
+// extension, for files whose names are ambiguous.
+func (c *Configuration) SetFormat(format Format) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if !c.formatOverride || c.format != format {
+		c.format, c.formatOverride = format, true
+		c.lastupdate = 0
+	}
+}
+
+func (c *Configuration) formatFor(filename string) Format {
+	if c.formatOverride {
+		return c.format
+	}
+	return DetectFormat(filename)
+}
+
+func (c *Configuration) currentFormat(filename string) Format {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.formatFor(filename)
+}
+
+// DetectFormat picks the registered format matching the extension of
+// filename, falling back to key=value for unregistered extensions.
+func DetectFormat(filename string) Format {
+	format := formatOf(filepath.Ext(filename))
+	formatsMutex.RLock()
+	defer formatsMutex.RUnlock()
+	if _, found := formats[format]; found {
+		return format
+	}
+	return FormatKeyValue
+}
+
+func (c *Configuration) decode(caller, filename string, format Format, r io.Reader) (parsed, error) {
+	formatsMutex.RLock()
+	p, found := formats[format]
+	formatsMutex.RUnlock()
+	if c.parser != nil {
+		p, found = c.parser, true
+	}
+	if !found {
+		return parsed{}, fmt.Errorf("unknown format '%s'", format)
+	} else if b, ok := p.(builtin); ok {
+		return c.decodeBuiltin(caller, filename, Format(b), r)
+	} else if values, err := p.Parse(r); err != nil {
+		return parsed{}, fmt.Errorf("decoding %s: %w", filename, err)
+	} else {
+		result := parsed{entries: make([]entry, 0, len(values))}
+		for _, key := range sortedKeys(values) {
+			result.entries = append(result.entries, entry{key: key, value: values[key], file: filename})
+		}
+		result.summary.Keys = len(values)
+		return result, nil
+	}
+}
+
+func (c *Configuration) decodeBuiltin(caller, filename string, format Format, r io.Reader) (parsed, error) {
+	switch format {
+	case FormatKeyValue:
+		return c.parse(caller, filename, r)
+	case FormatDotenv:
+		return c.parseDotenv(caller, filename, r)
+	case FormatJSON:
+		var document any
+		decoder := json.NewDecoder(r)
+		decoder.UseNumber()
+		if err := decoder.Decode(&document); err != nil {
+			return parsed{}, fmt.Errorf("decoding %s: %w", filename, err)
+		}
+		return flattened(filename, document), nil
+	case FormatYAML:
+		var document any
+		if err := yaml.NewDecoder(r).Decode(&document); err != nil && !errors.Is(err, io.EOF) {
+			return parsed{}, fmt.Errorf("decoding %s: %w", filename, err)
+		}
+		return flattened(filename, document), nil
+	case FormatTOML:
+		document := make(map[string]any)
+		if _, err := toml.NewDecoder(r).Decode(&document); err != nil {
+			return parsed{}, fmt.Errorf("decoding %s: %w", filename, err)
+		}
+		return flattened(filename, document), nil
+	default:
+		return parsed{}, fmt.Errorf("unknown format '%s'", format)
+	}
+}
+
+// flattened turns a decoded document into entries, joining nested map keys
+// with dots. Lists of scalars become comma-separated values; lists holding
+// maps or lists are indexed instead, as in servers.0.host.
+func flattened(filename string, document any) parsed {
+	values := make(map[string]string)
+	flatten("", document, values)
+	delete(values, "")
+	result := parsed{entries: make([]entry, 0, len(values))}
+	for _, key := range sortedKeys(values) {
+		result.entries = append(result.entries, entry{key: key, value: values[key], file: filename})
+	}
+	result.summary.Keys = len(values)
+	return result
+}
+
+func flatten(prefix string, value any, values map[string]string) {
+	join := func(key string) string {
+		if len(prefix) == 0 {
+			return key
+		}
+		return prefix + "." + key
+	}
+	switch v := value.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			flatten(join(key), v[key], values)
+		}
+	case map[any]any:
+		converted := make(map[string]any, len(v))
+		for key, element := range v {
+			converted[fmt.Sprint(key)] = element
+		}
+		flatten(prefix, converted, values)
+	case []map[string]any:
+		for i, element := range v {
+			flatten(join(fmt.Sprint(i)), element, values)
+		}
+	case []any:
+		scalars := make([]string, 0, len(v))
+		for _, element := range v {
+			switch element.(type) {
+			case map[string]any, map[any]any, []any:
+				for i, element := range v {
+					flatten(join(fmt.Sprint(i)), element, values)
+				}
+				return
+			default:
+				scalars = append(scalars, scalar(element))
+			}
+		}
+		values[prefix] = strings.Join(scalars, ",")
+	default:
+		values[prefix] = scalar(v)
+	}
+}
+
+func scalar(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprint(v)
+	}
+}
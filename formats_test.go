@@ -0,0 +1,172 @@
+package configuration
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestJSONFormat(t *testing.T) {
+	config, path := newTestConfiguration(t, "")
+	config.SetFormat(FormatJSON)
+	writeFile(t, path, `{
+	"name": "app",
+	"db": {"host": "localhost", "port": 5432, "tls": true},
+	"id": 9007199254740993,
+	"tags": ["a", "b"],
+	"servers": [{"host": "one"}, {"host": "two"}],
+	"empty": null
+}`)
+	config.Reload()
+	want := map[string]string{
+		"name":           "app",
+		"db.host":        "localhost",
+		"db.port":        "5432",
+		"db.tls":         "true",
+		"id":             "9007199254740993",
+		"tags":           "a,b",
+		"servers.0.host": "one",
+		"servers.1.host": "two",
+		"empty":          "",
+	}
+	for key, value := range want {
+		if got, found := config.Lookup(key); !found || got != value {
+			t.Errorf("%s = %q, %v, want %q", key, got, found, value)
+		}
+	}
+}
+
+func TestJSONFormatKeepsLastGood(t *testing.T) {
+	config, path := newTestConfiguration(t, "")
+	config.SetFormat(FormatJSON)
+	writeFile(t, path, `{"name": "app"}`)
+	config.Reload()
+	writeFile(t, path, `{"name": "broken"`)
+	config.Reload()
+	if got := config.Get("name"); got != "app" {
+		t.Errorf("name = %q after a malformed document, want app", got)
+	}
+}
+
+func TestYAMLFormat(t *testing.T) {
+	config, path := newTestConfiguration(t, "")
+	config.SetFormat(FormatYAML)
+	writeFile(t, path, "name: app\ndb:\n  host: localhost\n  port: 5432\n1: numeric\ntags: [a, b]\nservers:\n  - host: one\n  - host: two\ncreated: 2024-06-01T12:00:00Z\n")
+	config.Reload()
+	want := map[string]string{
+		"name":           "app",
+		"db.host":        "localhost",
+		"db.port":        "5432",
+		"1":              "numeric",
+		"tags":           "a,b",
+		"servers.0.host": "one",
+		"servers.1.host": "two",
+		"created":        "2024-06-01T12:00:00Z",
+	}
+	for key, value := range want {
+		if got, found := config.Lookup(key); !found || got != value {
+			t.Errorf("%s = %q, %v, want %q", key, got, found, value)
+		}
+	}
+	writeFile(t, path, "")
+	config.Reload()
+	if got := config.Get("name"); got != "app" {
+		t.Errorf("name = %q after an empty document, want app", got)
+	}
+}
+
+func TestTOMLFormat(t *testing.T) {
+	config, path := newTestConfiguration(t, "")
+	config.SetFormat(FormatTOML)
+	writeFile(t, path, "name = \"app\"\ntags = [\"a\", \"b\"]\n\n[db]\nhost = \"localhost\"\nport = 5432\n\n[[servers]]\nhost = \"one\"\n\n[[servers]]\nhost = \"two\"\n")
+	config.Reload()
+	want := map[string]string{
+		"name":           "app",
+		"tags":           "a,b",
+		"db.host":        "localhost",
+		"db.port":        "5432",
+		"servers.0.host": "one",
+		"servers.1.host": "two",
+	}
+	for key, value := range want {
+		if got, found := config.Lookup(key); !found || got != value {
+			t.Errorf("%s = %q, %v, want %q", key, got, found, value)
+		}
+	}
+}
+
+func TestRegisterFormat(t *testing.T) {
+	RegisterFormat(".Lines", ParserFunc(func(r io.Reader) (map[string]string, error) {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		results := make(map[string]string)
+		for i, line := range strings.Fields(string(data)) {
+			results[fmt.Sprintf("line%d", i)] = line
+		}
+		return results, nil
+	}))
+	t.Cleanup(func() {
+		formatsMutex.Lock()
+		defer formatsMutex.Unlock()
+		delete(formats, "lines")
+	})
+	config, path := newTestConfiguration(t, "")
+	config.SetFormat("lines")
+	writeFile(t, path, "alpha beta\n")
+	config.Reload()
+	if got := config.Get("line0"); got != "alpha" {
+		t.Errorf("line0 = %q, want alpha", got)
+	} else if got := config.Get("line1"); got != "beta" {
+		t.Errorf("line1 = %q, want beta", got)
+	}
+	if _, err := config.decode("test", path, "unregistered", strings.NewReader("")); err == nil {
+		t.Error("decode accepted an unregistered format")
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	tests := map[string]Format{
+		"app.conf":      FormatKeyValue,
+		"app":           FormatKeyValue,
+		"app.json":      FormatJSON,
+		"/etc/app.YAML": FormatYAML,
+		"app.yml":       "yml",
+		"app.toml":      FormatTOML,
+		"app.env":       FormatDotenv,
+	}
+	for filename, want := range tests {
+		if got := DetectFormat(filename); got != want {
+			t.Errorf("DetectFormat(%s) = %q, want %q", filename, got, want)
+		}
+	}
+}
+
+func TestFormatFromExtension(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.json")
+	writeFile(t, path, `{"db": {"host": "localhost"}}`)
+	config := NewWithOptions(context.Background(), path, WithLogger(&testLogger{}))
+	defer config.Close()
+	if got := config.Get("db.host"); got != "localhost" {
+		t.Errorf("db.host = %q from a .json file, want localhost", got)
+	}
+	yamlPath := filepath.Join(filepath.Dir(path), "app.yaml")
+	writeFile(t, yamlPath, "db:\n  host: remote\n")
+	if err := config.SwapFile(yamlPath); err != nil {
+		t.Fatal(err)
+	} else if got := config.Get("db.host"); got != "remote" {
+		t.Errorf("db.host = %q after swapping to a .yaml file, want remote", got)
+	}
+	ambiguous := filepath.Join(filepath.Dir(path), "app.cfg")
+	writeFile(t, ambiguous, "db:\n  host: override\n")
+	config.SetFormat(FormatYAML)
+	if err := config.SwapFile(ambiguous); err != nil {
+		t.Fatal(err)
+	} else if got := config.Get("db.host"); got != "override" {
+		t.Errorf("db.host = %q with SetFormat overriding the extension, want override", got)
+	}
+}
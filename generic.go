@@ -0,0 +1,67 @@
+package configuration
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+func Get[T any](c *Configuration, key string) (T, error) {
+	var t T
+	var err error
+	switch p := any(&t).(type) {
+	case *string:
+		*p, err = c.lookup(key)
+	case *int:
+		*p, err = c.GetInt(key)
+	case *int64:
+		*p, err = c.GetInt64(key)
+	case *bool:
+		*p, err = c.GetBool(key)
+	case *float64:
+		*p, err = c.GetFloat64(key)
+	case *time.Duration:
+		*p, err = c.GetDuration(key)
+	case encoding.TextUnmarshaler:
+		var value string
+		if value, err = c.lookup(key); err == nil {
+			if err = p.UnmarshalText([]byte(value)); err != nil {
+				err = fmt.Errorf("key '%s': %w", key, err)
+			}
+		}
+	default:
+		err = fmt.Errorf("key '%s': unsupported type %T", key, t)
+	}
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return t, nil
+}
+
+func GetOr[T any](c *Configuration, key string, def T, parse func(string) (T, error)) T {
+	if value, err := c.lookup(key); err != nil {
+		return def
+	} else if t, err := parse(value); err != nil {
+		return def
+	} else {
+		return t
+	}
+}
+
+func GetJSONArray[T any](c *Configuration, key string) ([]T, error) {
+	results := make([]T, 0)
+	value := strings.TrimSpace(c.Get(key))
+	if len(value) == 0 {
+		return results, nil
+	} else if err := json.Unmarshal([]byte(value), &results); err != nil {
+		return nil, fmt.Errorf("key '%s': %w", key, err)
+	}
+	return results, nil
+}
+
+func (c *Configuration) GetIntArrayJSON(key string) ([]int, error) {
+	return GetJSONArray[int](c, key)
+}
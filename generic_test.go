@@ -0,0 +1,85 @@
+package configuration
+
+import (
+	"errors"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+type endpoint struct {
+	host string
+	port int
+}
+
+func parseEndpoint(s string) (endpoint, error) {
+	host, port, found := strings.Cut(s, "@")
+	if !found {
+		return endpoint{}, errors.New("missing '@'")
+	}
+	n, err := strconv.Atoi(port)
+	return endpoint{host: host, port: n}, err
+}
+
+func TestGetOr(t *testing.T) {
+	config, _ := newTestConfiguration(t, "workers=8\nbad_workers=eight\nprimary=db@5432\nbad_primary=db\n")
+	if got := GetOr(config, "workers", 1, strconv.Atoi); got != 8 {
+		t.Errorf("GetOr(workers) = %d, want 8", got)
+	} else if got := GetOr(config, "bad_workers", 1, strconv.Atoi); got != 1 {
+		t.Errorf("GetOr(bad_workers) = %d, want the default", got)
+	} else if got := GetOr(config, "missing", 1, strconv.Atoi); got != 1 {
+		t.Errorf("GetOr(missing) = %d, want the default", got)
+	}
+	def := endpoint{host: "localhost", port: 1}
+	if got := GetOr(config, "primary", def, parseEndpoint); got != (endpoint{host: "db", port: 5432}) {
+		t.Errorf("GetOr(primary) = %+v", got)
+	} else if got := GetOr(config, "bad_primary", def, parseEndpoint); got != def {
+		t.Errorf("GetOr(bad_primary) = %+v, want the default", got)
+	}
+}
+
+func TestGetIntArrayJSON(t *testing.T) {
+	config, _ := newTestConfiguration(t, "ports=[80, 443, 8080]\nbad=[80, 443\n")
+	if got, err := config.GetIntArrayJSON("ports"); err != nil {
+		t.Errorf("GetIntArrayJSON: %v", err)
+	} else if !slices.Equal(got, []int{80, 443, 8080}) {
+		t.Errorf("GetIntArrayJSON = %v, want [80 443 8080]", got)
+	}
+	if _, err := config.GetIntArrayJSON("bad"); err == nil {
+		t.Error("GetIntArrayJSON accepted a malformed array")
+	}
+	if got, err := GetJSONArray[string](config, "missing"); err != nil || got == nil || len(got) != 0 {
+		t.Errorf("GetJSONArray of a missing key = %v, %v, want an empty slice", got, err)
+	}
+}
+
+func TestGet(t *testing.T) {
+	config, _ := newTestConfiguration(t, "name=app\nport=8080\ndebug=true\nratio=0.5\ntimeout=2s\naddr=10.0.0.1\nbad=x\n")
+	if got, err := Get[string](config, "name"); err != nil || got != "app" {
+		t.Errorf("Get[string] = %q, %v, want app", got, err)
+	}
+	if got, err := Get[int](config, "port"); err != nil || got != 8080 {
+		t.Errorf("Get[int] = %d, %v, want 8080", got, err)
+	}
+	if got, err := Get[bool](config, "debug"); err != nil || !got {
+		t.Errorf("Get[bool] = %v, %v, want true", got, err)
+	}
+	if got, err := Get[float64](config, "ratio"); err != nil || got != 0.5 {
+		t.Errorf("Get[float64] = %v, %v, want 0.5", got, err)
+	}
+	if got, err := Get[time.Duration](config, "timeout"); err != nil || got != 2*time.Second {
+		t.Errorf("Get[time.Duration] = %v, %v, want 2s", got, err)
+	}
+	if got, err := Get[netip.Addr](config, "addr"); err != nil || got != netip.MustParseAddr("10.0.0.1") {
+		t.Errorf("Get[netip.Addr] = %v, %v, want 10.0.0.1", got, err)
+	}
+	if got, err := Get[netip.Addr](config, "bad"); err == nil || got.IsValid() {
+		t.Errorf("Get[netip.Addr] of a malformed value = %v, %v, want the zero value and an error", got, err)
+	}
+	if _, err := Get[[]byte](config, "name"); err == nil {
+		t.Error("Get accepted an unsupported type")
+	}
+}
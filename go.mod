@@ -2,4 +2,11 @@ module github.com/sharkpick/configuration
 
 go 1.21.3
 
-require github.com/sharkpick/channels v0.0.0-20240219182216-b0330a426b22 // indirect
+require (
+	github.com/BurntSushi/toml v1.6.0
+	github.com/fsnotify/fsnotify v1.7.0
+	github.com/sharkpick/channels v0.0.0-20240219182216-b0330a426b22
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require golang.org/x/sys v0.4.0 // indirect
@@ -0,0 +1,103 @@
+package configuration
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"time"
+)
+
+var (
+	ErrNoRevision = errors.New("no such revision")
+)
+
+// Revision is the full set of parameters as of one Version, with the time
+// and the source (update, SetKeyValue, Commit, ...) of the change that
+// produced it.
+type Revision struct {
+	Version    int64
+	Time       time.Time
+	Source     string
+	Parameters map[string]string
+}
+
+// SetHistory keeps the last size revisions for SnapshotAt and Rollback.
+// Zero, the default, keeps none.
+func (c *Configuration) SetHistory(size int) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.historySize = max(0, size)
+	if len(c.history) > c.historySize {
+		c.history = append([]Revision{}, c.history[len(c.history)-c.historySize:]...)
+	}
+	if c.historySize > 0 && len(c.history) == 0 {
+		c.history = append(c.history, Revision{Version: c.version.Load(), Time: c.clock(), Source: "SetHistory", Parameters: maps.Clone(c.parameters)})
+	}
+}
+
+func WithHistory(size int) Option {
+	return func(c *Configuration) {
+		c.historySize = max(0, size)
+	}
+}
+
+// History returns the kept revisions, oldest first.
+func (c *Configuration) History() []Revision {
+	c = c.origin()
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	results := make([]Revision, 0, len(c.history))
+	for _, r := range c.history {
+		r.Parameters = maps.Clone(r.Parameters)
+		results = append(results, r)
+	}
+	return results
+}
+
+func (c *Configuration) SnapshotAt(version int64) (Revision, bool) {
+	c = c.origin()
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	for _, r := range c.history {
+		if r.Version == version {
+			r.Parameters = maps.Clone(r.Parameters)
+			return r, true
+		}
+	}
+	return Revision{}, false
+}
+
+// Rollback restores the parameters as they were n revisions ago, so that
+// Rollback(1) undoes the most recent change. The rollback is itself a new
+// revision, and the next change to the file is applied over it as usual.
+func (c *Configuration) Rollback(n int) error {
+	c.mutex.Lock()
+	defer c.release()
+	if n < 1 || n >= len(c.history) {
+		return fmt.Errorf("%w: %d back of %d kept", ErrNoRevision, n, len(c.history))
+	}
+	target := c.history[len(c.history)-1-n]
+	c.logf("Configuration::Rollback restoring version %d from %v\n", target.Version, target.Time)
+	for _, key := range sortedKeys(c.parameters) {
+		if _, found := target.Parameters[key]; !found {
+			c.remove("Rollback", key)
+		}
+	}
+	for _, key := range sortedKeys(target.Parameters) {
+		delete(c.origins, key)
+		c.store("Rollback", key, target.Parameters[key])
+	}
+	return nil
+}
+
+// revise appends the current parameters as a new revision, dropping the
+// oldest once the history is full. The caller holds the write lock.
+func (c *Configuration) revise(events []Event) {
+	if c.historySize == 0 || len(events) == 0 {
+		return
+	}
+	c.history = append(c.history, Revision{Version: c.version.Load(), Time: events[len(events)-1].Time, Source: events[len(events)-1].Source, Parameters: maps.Clone(c.parameters)})
+	if len(c.history) > c.historySize {
+		c.history = append([]Revision{}, c.history[len(c.history)-c.historySize:]...)
+	}
+}
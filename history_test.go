@@ -0,0 +1,57 @@
+package configuration
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestHistory(t *testing.T) {
+	config, path := newTestConfiguration(t, "key=1\n")
+	config.SetHistory(3)
+	initial := config.Version()
+	for _, value := range []string{"2", "3", "4"} {
+		writeFile(t, path, "key="+value+"\n")
+		config.Reload()
+	}
+	history := config.History()
+	if len(history) != 3 {
+		t.Fatalf("History kept %d revisions, want 3", len(history))
+	} else if history[0].Parameters["key"] != "2" || history[2].Parameters["key"] != "4" {
+		t.Errorf("History = %+v, want key 2 through 4", history)
+	}
+	if _, found := config.SnapshotAt(initial); found {
+		t.Error("SnapshotAt found a revision that fell out of the history")
+	}
+	if revision, found := config.SnapshotAt(initial + 2); !found || revision.Parameters["key"] != "3" {
+		t.Errorf("SnapshotAt(%d) = %+v, %v, want key 3", initial+2, revision, found)
+	}
+	revision, _ := config.SnapshotAt(initial + 3)
+	revision.Parameters["key"] = "mutated"
+	if again, _ := config.SnapshotAt(initial + 3); again.Parameters["key"] != "4" {
+		t.Error("SnapshotAt returned the stored map")
+	}
+}
+
+func TestRollback(t *testing.T) {
+	config, path := newTestConfiguration(t, "a=1\n", WithHistory(5))
+	writeFile(t, path, "a=2\nb=2\n")
+	config.Reload()
+	if err := config.Rollback(1); err != nil {
+		t.Fatal(err)
+	}
+	if got := config.Get("a"); got != "1" {
+		t.Errorf("a = %q after Rollback(1), want 1", got)
+	} else if _, found := config.Lookup("b"); found {
+		t.Error("Rollback kept a key added by the undone change")
+	}
+	if err := config.Rollback(1); err != nil {
+		t.Fatal(err)
+	} else if got := config.Get("b"); got != "2" {
+		t.Errorf("b = %q after rolling back the rollback, want 2", got)
+	}
+	if err := config.Rollback(10); !errors.Is(err, ErrNoRevision) {
+		t.Errorf("Rollback past the history = %v, want %v", err, ErrNoRevision)
+	} else if err := config.Rollback(0); !errors.Is(err, ErrNoRevision) {
+		t.Errorf("Rollback(0) = %v, want %v", err, ErrNoRevision)
+	}
+}
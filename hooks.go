@@ -0,0 +1,72 @@
+package configuration
+
+import "errors"
+
+// BeforeReload registers fn to run each time a changed file is about to be
+// applied, with the name of the file. Hooks run without the lock held and
+// may read the current, not yet reloaded, values.
+func (c *Configuration) BeforeReload(fn func(source string)) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.beforeHooks = append(c.beforeHooks, fn)
+}
+
+// AfterReload registers fn to run once a reload has been applied, with the
+// sorted keys whose values it added, changed or removed.
+func (c *Configuration) AfterReload(fn func(changed []string)) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.afterHooks = append(c.afterHooks, fn)
+}
+
+// errBeforeReload tells reload that update found a change for source but
+// the BeforeReload hooks have yet to run.
+var errBeforeReload = errors.New("before reload hooks pending")
+
+// beforeReload returns errBeforeReload when there are hooks that have not
+// yet run for this reload. The caller holds the write lock.
+func (c *Configuration) beforeReload(source string) error {
+	if len(c.beforeHooks) == 0 || c.hooksRan {
+		return nil
+	}
+	c.beforeSource = source
+	return errBeforeReload
+}
+
+// runBeforeReload runs the BeforeReload hooks for a load that is not driven
+// by reload. The caller must not hold the lock.
+func (c *Configuration) runBeforeReload(source string) {
+	c.mutex.RLock()
+	hooks := append([]func(string){}, c.beforeHooks...)
+	c.mutex.RUnlock()
+	for _, hook := range hooks {
+		hook(source)
+	}
+}
+
+// OnChangeSet registers fn to run once a reload has been applied, with the
+// full diff against the previous parameters.
+func (c *Configuration) OnChangeSet(fn func(ChangeSet)) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.changeSetHooks = append(c.changeSetHooks, fn)
+}
+
+// reloaded records the diff between previous and the parameters now
+// stored as the last ChangeSet, queueing it for release to hand to the
+// reload hooks.
+func (c *Configuration) reloaded(source string, previous map[string]string) {
+	c.lastChanges = diff(c.clock(), source, previous, c.parameters)
+	if len(c.afterHooks) > 0 || len(c.changeSetHooks) > 0 {
+		c.afterPending = append(c.afterPending, c.lastChanges)
+	}
+}
+
+func (c *Configuration) takeReloaded() ([]func([]string), []func(ChangeSet), []ChangeSet) {
+	if len(c.afterPending) == 0 {
+		return nil, nil, nil
+	}
+	pending := c.afterPending
+	c.afterPending = nil
+	return append([]func([]string){}, c.afterHooks...), append([]func(ChangeSet){}, c.changeSetHooks...), pending
+}
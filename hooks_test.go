@@ -0,0 +1,58 @@
+package configuration
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestReloadHooks(t *testing.T) {
+	config, path := newTestConfiguration(t, "a=1\nb=1\nc=1\n", WithPruneRemoved())
+	var (
+		sources []string
+		seen    string
+		changed [][]string
+	)
+	config.BeforeReload(func(source string) {
+		sources = append(sources, source)
+		seen = config.Get("a")
+	})
+	config.AfterReload(func(keys []string) {
+		changed = append(changed, keys)
+	})
+	writeFile(t, path, "a=2\nb=1\nd=1\n")
+	config.Reload()
+	if len(sources) != 1 || sources[0] != path {
+		t.Errorf("BeforeReload sources = %v, want %s", sources, path)
+	} else if seen != "1" {
+		t.Errorf("BeforeReload read a = %q, want the value before the reload", seen)
+	}
+	if len(changed) != 1 || !slices.Equal(changed[0], []string{"a", "c", "d"}) {
+		t.Errorf("AfterReload changed = %v, want [a c d]", changed)
+	}
+	config.Reload()
+	if len(changed) != 2 || len(changed[1]) != 0 {
+		t.Errorf("AfterReload after an unchanged reload = %v, want an empty key list", changed)
+	}
+	config.SetKeyValue("e", "1")
+	if len(changed) != 2 {
+		t.Errorf("AfterReload fired for SetKeyValue: %v", changed)
+	}
+}
+
+func TestBeforeReloadRunsUnlocked(t *testing.T) {
+	config, path := newTestConfiguration(t, "a=1\n")
+	ran := 0
+	config.BeforeReload(func(string) {
+		if ran++; ran == 1 {
+			config.SetKeyValue("hooked", "yes")
+			writeFile(t, path, "a=3\n")
+		}
+	})
+	writeFile(t, path, "a=2\n")
+	config.Reload()
+	if got := config.Get("a"); got != "3" {
+		t.Errorf("a = %q, want the file as rewritten by the hook, not as read before it", got)
+	} else if got := config.Get("hooked"); got != "yes" {
+		t.Errorf("hooked = %q, want the value the hook set", got)
+	}
+}
@@ -0,0 +1,112 @@
+package configuration
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// dependency records an include pattern and the modification times of the
+// files it matched, so that editing, adding or removing a fragment triggers
+// a reload just like editing the main file.
+type dependency struct {
+	pattern string
+	files   map[string]int64
+}
+
+// includeDirective recognizes lines of the form "include path/*.conf". A
+// line such as "include = yes" is an ordinary key named include.
+func includeDirective(line, delimiters string) (string, bool) {
+	rest, found := strings.CutPrefix(strings.TrimSpace(line), "include")
+	if !found || len(rest) == 0 || (rest[0] != ' ' && rest[0] != '\t') {
+		return "", false
+	} else if rest = strings.TrimSpace(rest); len(rest) == 0 || strings.IndexAny(rest[:1], delimiters) == 0 {
+		return "", false
+	}
+	return unquote(rest), true
+}
+
+// include parses every file matching pattern, in lexical order, into
+// result. Relative patterns are resolved against the directory of the
+// including file, and keys from later files override earlier ones.
+func (c *Configuration) include(result *parsed, caller, filename, pattern string, seen map[string]struct{}, stack []string) error {
+	if !filepath.IsAbs(pattern) && len(filename) > 0 {
+		pattern = filepath.Join(filepath.Dir(filename), pattern)
+	}
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return fmt.Errorf("include '%s': %w", pattern, err)
+	}
+	sort.Strings(matches)
+	if len(filename) > 0 {
+		if absolute, err := filepath.Abs(filename); err == nil {
+			stack = append(stack, absolute)
+		}
+	}
+	dep := dependency{pattern: pattern, files: make(map[string]int64, len(matches))}
+	defer func() { result.includes = append(result.includes, dep) }()
+	for _, match := range matches {
+		absolute, err := filepath.Abs(match)
+		if err != nil {
+			return fmt.Errorf("include '%s': %w", match, err)
+		}
+		for _, parent := range stack {
+			if parent == absolute {
+				return fmt.Errorf("include '%s': include cycle", match)
+			}
+		}
+		stat, err := os.Stat(match)
+		if err != nil {
+			return fmt.Errorf("include '%s': %w", match, err)
+		} else if stat.IsDir() {
+			continue
+		}
+		f, err := os.Open(match)
+		if err != nil {
+			return fmt.Errorf("include '%s': %w", match, err)
+		}
+		dep.files[match] = stat.ModTime().UnixNano()
+		included, err := c.parseFile(caller, match, f, seen, stack)
+		f.Close()
+		result.entries = append(result.entries, included.entries...)
+		result.includes = append(result.includes, included.includes...)
+		result.summary.Comments += included.summary.Comments
+		result.summary.Duplicates += included.summary.Duplicates
+		result.summary.Skipped += included.summary.Skipped
+		for _, e := range included.errors {
+			result.errors = append(result.errors, fmt.Errorf("%s: %w", match, e))
+		}
+		if err != nil {
+			return fmt.Errorf("include '%s': %w", match, err)
+		}
+	}
+	return nil
+}
+
+// includesChanged reports whether any included file was modified, or any
+// include pattern now matches a different set of files.
+func (c *Configuration) includesChanged() bool {
+	for _, dep := range c.includes {
+		matches, err := filepath.Glob(dep.pattern)
+		if err != nil {
+			continue
+		}
+		count := 0
+		for _, match := range matches {
+			stat, err := os.Stat(match)
+			if err != nil || stat.IsDir() {
+				continue
+			}
+			count++
+			if mtime, found := dep.files[match]; !found || mtime != stat.ModTime().UnixNano() {
+				return true
+			}
+		}
+		if count != len(dep.files) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+package configuration
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestInclude(t *testing.T) {
+	config, path := newTestConfiguration(t, "")
+	dir := filepath.Dir(path)
+	if err := os.Mkdir(filepath.Join(dir, "conf.d"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(dir, "conf.d", "10-db.conf"), "db.host=localhost\nshared=first\n")
+	writeFile(t, filepath.Join(dir, "conf.d", "20-cache.conf"), "cache.size=10\nshared=second\n")
+	writeFile(t, path, "name=app\ninclude conf.d/*.conf\ninclude = yes\n")
+	config.Update()
+	want := map[string]string{"name": "app", "db.host": "localhost", "cache.size": "10", "shared": "second", "include": "yes"}
+	for key, value := range want {
+		if got, found := config.Lookup(key); !found || got != value {
+			t.Errorf("%s = %q, %v, want %q", key, got, found, value)
+		}
+	}
+	if file, line, _ := config.Origin("cache.size"); file != filepath.Join(dir, "conf.d", "20-cache.conf") || line != 1 {
+		t.Errorf("Origin(cache.size) = %s:%d, want the fragment", file, line)
+	}
+	writeFile(t, filepath.Join(dir, "conf.d", "10-db.conf"), "db.host=remote\n")
+	config.Update()
+	if got := config.Get("db.host"); got != "remote" {
+		t.Errorf("db.host = %q after editing a fragment, want remote", got)
+	}
+	writeFile(t, filepath.Join(dir, "conf.d", "30-new.conf"), "added=1\n")
+	config.Update()
+	if got := config.Get("added"); got != "1" {
+		t.Errorf("added = %q after adding a fragment, want 1", got)
+	}
+}
+
+func TestIncludeCycle(t *testing.T) {
+	config, path := newTestConfiguration(t, "", WithErrorThreshold(-1))
+	dir := filepath.Dir(path)
+	writeFile(t, filepath.Join(dir, "other.conf"), "other=1\ninclude test.conf\n")
+	writeFile(t, path, "main=1\ninclude other.conf\n")
+	config.Update()
+	if summary := config.LastParseSummary(); summary.Errors == 0 {
+		t.Errorf("summary = %+v, want the include cycle reported", summary)
+	}
+	if got := config.Get("main"); got != "1" {
+		t.Errorf("main = %q, want the keys before the cycle loaded", got)
+	}
+}
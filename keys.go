@@ -0,0 +1,52 @@
+package configuration
+
+import (
+	"maps"
+	"strings"
+)
+
+// Keys returns the loaded keys, sorted.
+func (c *Configuration) Keys() []string {
+	c = c.origin()
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return sortedKeys(c.parameters)
+}
+
+// KeysWithPrefix returns the sorted keys starting with prefix, such as the
+// route.* family for prefix "route.".
+func (c *Configuration) KeysWithPrefix(prefix string) []string {
+	c = c.origin()
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	results := make([]string, 0)
+	for _, key := range sortedKeys(c.parameters) {
+		if strings.HasPrefix(key, prefix) {
+			results = append(results, key)
+		}
+	}
+	return results
+}
+
+// Range calls fn for each parameter in key order until fn returns false.
+// It iterates over a copy taken at the start, so fn may call back into c.
+func (c *Configuration) Range(fn func(key, value string) bool) {
+	c = c.origin()
+	c.mutex.RLock()
+	parameters := maps.Clone(c.parameters)
+	c.mutex.RUnlock()
+	for _, key := range sortedKeys(parameters) {
+		if !fn(key, parameters[key]) {
+			return
+		}
+	}
+}
+
+// Snapshot returns a copy of every parameter as of one instant, safe to
+// keep and modify while reloads continue.
+func (c *Configuration) Snapshot() map[string]string {
+	c = c.origin()
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return maps.Clone(c.parameters)
+}
@@ -0,0 +1,49 @@
+package configuration
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestKeys(t *testing.T) {
+	config, _ := newTestConfiguration(t, "route.b=2\nname=svc\nroute.a=1\n")
+	if got, want := config.Keys(), []string{"name", "route.a", "route.b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Keys = %v, want %v", got, want)
+	}
+	if got, want := config.KeysWithPrefix("route."), []string{"route.a", "route.b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("KeysWithPrefix(route.) = %v, want %v", got, want)
+	}
+	if got := config.KeysWithPrefix("missing."); got == nil || len(got) != 0 {
+		t.Errorf("KeysWithPrefix with no matches = %#v, want an empty non-nil slice", got)
+	}
+}
+
+func TestRange(t *testing.T) {
+	config, _ := newTestConfiguration(t, "b=2\na=1\nc=3\n")
+	var seen []string
+	config.Range(func(key, value string) bool {
+		seen = append(seen, key+"="+value)
+		config.SetKeyValue("d", "4") // calling back into config must not deadlock
+		return key != "b"
+	})
+	if want := []string{"a=1", "b=2"}; !reflect.DeepEqual(seen, want) {
+		t.Errorf("Range visited %v, want %v stopping after b", seen, want)
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	config, path := newTestConfiguration(t, "a=1\n")
+	snapshot := config.Snapshot()
+	snapshot["a"] = "changed"
+	if got := config.Get("a"); got != "1" {
+		t.Errorf("modifying the snapshot changed a to %q", got)
+	}
+	writeFile(t, path, "a=2\n")
+	config.Reload()
+	if got := config.Snapshot()["a"]; got != "2" {
+		t.Errorf("Snapshot after reload has a=%q, want 2", got)
+	}
+	if snapshot["a"] != "changed" {
+		t.Errorf("reload changed an earlier snapshot to %q", snapshot["a"])
+	}
+}
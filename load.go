@@ -0,0 +1,63 @@
+package configuration
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+func (c *Configuration) LoadFromReader(r io.Reader) error {
+	result, err := c.decode("LoadFromReader", "", c.currentFormat(""), r)
+	if err != nil {
+		return err
+	}
+	c.runBeforeReload("reader")
+	c.mutex.Lock()
+	c.summarize("LoadFromReader", "reader", result)
+	if err = c.tolerate("LoadFromReader", "reader", result.errors, -1); err == nil {
+		err = c.apply("LoadFromReader", "reader", result.entries, false)
+	}
+	c.release()
+	c.reloadError(err)
+	return err
+}
+
+func (c *Configuration) LoadBytes(data []byte) error {
+	return c.LoadFromReader(bytes.NewReader(data))
+}
+
+func (c *Configuration) SwapFile(newPath string) error {
+	path, target := newPath, ""
+	if c.FollowSymlinks.Load() {
+		if resolved, err := filepath.EvalSymlinks(newPath); err == nil {
+			path, target = resolved, resolved
+		}
+	}
+	stat, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	result, err := c.decode("SwapFile", newPath, c.currentFormat(newPath), bytes.NewReader(data))
+	if err != nil {
+		return err
+	} else if err := c.tolerate("SwapFile", newPath, result.errors, 0); err != nil {
+		return err
+	}
+	c.runBeforeReload(newPath)
+	c.mutex.Lock()
+	c.summarize("SwapFile", newPath, result)
+	if err = c.apply("SwapFile", newPath, result.entries, true); err == nil {
+		c.filename, c.target, c.swapped = newPath, target, false
+		c.lastupdate, c.includes, c.hash = stat.ModTime().UnixNano(), result.includes, sha256.Sum256(data)
+		c.writeCache()
+	}
+	c.release()
+	c.reloadError(err)
+	return err
+}
@@ -0,0 +1,84 @@
+package configuration
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadBytes(t *testing.T) {
+	config, _ := newTestConfiguration(t, "existing=1\n")
+	if err := config.LoadBytes([]byte("host=db\nport=5432\n")); err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]string{"existing": "1", "host": "db", "port": "5432"} {
+		if got := config.Get(key); got != want {
+			t.Errorf("%s = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestSwapFile(t *testing.T) {
+	config, path := newTestConfiguration(t, "shared=old\nonly_old=1\n")
+	dir := filepath.Dir(path)
+	invalid := filepath.Join(dir, "invalid.conf")
+	writeFile(t, invalid, "shared=broken\nthis line has no delimiter\n")
+	if err := config.SwapFile(invalid); err == nil {
+		t.Error("SwapFile accepted a file with a parse error")
+	} else if config.Get("shared") != "old" || filenameOf(config) != path {
+		t.Errorf("a failed SwapFile changed the configuration: shared=%q file=%s", config.Get("shared"), filenameOf(config))
+	}
+
+	valid := filepath.Join(dir, "valid.conf")
+	writeFile(t, valid, "shared=new\nonly_new=2\n")
+	if err := config.SwapFile(valid); err != nil {
+		t.Fatal(err)
+	}
+	if config.Get("shared") != "new" || config.Get("only_new") != "2" || config.Get("only_old") != "" {
+		t.Errorf("after SwapFile: shared=%q only_new=%q only_old=%q", config.Get("shared"), config.Get("only_new"), config.Get("only_old"))
+	} else if filenameOf(config) != valid {
+		t.Errorf("filename = %s after SwapFile, want %s", filenameOf(config), valid)
+	}
+}
+
+func filenameOf(c *Configuration) string {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.filename
+}
+
+func TestSwapFileRecordsHash(t *testing.T) {
+	config, path := newTestConfiguration(t, "key=old\n", WithHashContents())
+	swapped := filepath.Join(filepath.Dir(path), "swapped.conf")
+	writeFile(t, swapped, "key=swapped\n")
+	if err := config.SwapFile(swapped); err != nil {
+		t.Fatal(err)
+	}
+	reloads := config.Status().Reloads
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(swapped, later, later); err != nil {
+		t.Fatal(err)
+	}
+	config.Update()
+	if got := config.Status().Reloads; got != reloads {
+		t.Errorf("Reloads = %d after touching the swapped file, want %d", got, reloads)
+	}
+}
+
+func TestSwapFileRecordsSymlinkTarget(t *testing.T) {
+	config, path := newTestConfiguration(t, "key=old\n", WithFollowSymlinks())
+	dir := filepath.Dir(path)
+	real, link := filepath.Join(dir, "real.conf"), filepath.Join(dir, "link.conf")
+	writeFile(t, real, "key=swapped\n")
+	if err := os.Symlink(real, link); err != nil {
+		t.Fatal(err)
+	} else if err := config.SwapFile(link); err != nil {
+		t.Fatal(err)
+	}
+	reloads := config.Status().Reloads
+	config.Update()
+	if got := config.Status().Reloads; got != reloads {
+		t.Errorf("Reloads = %d after polling an unchanged symlink, want %d", got, reloads)
+	}
+}
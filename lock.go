@@ -0,0 +1,33 @@
+package configuration
+
+// Lock pins key at its current value: reloads neither change nor remove it
+// until Unlock is called. SetKeyValue and transactions are unaffected.
+func (c *Configuration) Lock(key string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.locked == nil {
+		c.locked = make(map[string]struct{})
+	}
+	c.locked[key] = struct{}{}
+}
+
+func (c *Configuration) Unlock(key string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	delete(c.locked, key)
+}
+
+func (c *Configuration) unlocked(caller string, entries []entry) []entry {
+	if len(c.locked) == 0 {
+		return entries
+	}
+	results := make([]entry, 0, len(entries))
+	for _, e := range entries {
+		if _, locked := c.locked[e.key]; !locked {
+			results = append(results, e)
+		} else if stored := c.parameters[e.key]; stored != e.value {
+			c.logf("Configuration::%s suppressing change to locked key '%s' from '%s' to '%s'\n", caller, escape(e.key), escape(stored), escape(e.value))
+		}
+	}
+	return results
+}
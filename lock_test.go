@@ -0,0 +1,36 @@
+package configuration
+
+import (
+	"testing"
+)
+
+func TestLockKeepsValueAcrossReload(t *testing.T) {
+	config, path := newTestConfiguration(t, "pinned=1\nfree=1\n")
+	config.Lock("pinned")
+	writeFile(t, path, "pinned=2\nfree=2\n")
+	config.Reload()
+	if got := config.Get("pinned"); got != "1" {
+		t.Errorf("locked key = %q after reload, want 1", got)
+	} else if got := config.Get("free"); got != "2" {
+		t.Errorf("unlocked key = %q after reload, want 2", got)
+	}
+	config.Unlock("pinned")
+	config.Reload()
+	if got := config.Get("pinned"); got != "2" {
+		t.Errorf("key = %q after Unlock and reload, want 2", got)
+	}
+}
+
+func TestLockSurvivesRemoval(t *testing.T) {
+	config, path := newTestConfiguration(t, "pinned=1\nfree=1\n")
+	config.Lock("pinned")
+	writeFile(t, path, "free=2\n")
+	config.Reload()
+	if got := config.Get("pinned"); got != "1" {
+		t.Errorf("locked key = %q after removal from the file, want 1", got)
+	}
+	config.SetKeyValue("pinned", "3")
+	if got := config.Get("pinned"); got != "3" {
+		t.Errorf("SetKeyValue on a locked key = %q, want 3", got)
+	}
+}
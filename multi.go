@@ -0,0 +1,124 @@
+package configuration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// NewMulti watches several files, merging them in order so that a key in a
+// later file overrides the same key in an earlier one. A path may be a glob
+// such as conf.d/*.conf, whose matches are merged in lexical order and
+// expanded again on every poll. A change to any of the files, or to the set
+// of files a glob matches, reloads them all; missing files are skipped.
+func NewMulti(ctx context.Context, paths ...string) *Configuration {
+	config := &Configuration{
+		paths:      append([]string{}, paths...),
+		parameters: make(map[string]string),
+	}
+	config.ShouldLogUpdates.Store(DefaultShouldLog)
+	config.start(ctx)
+	return config
+}
+
+// noFiles stands in for the modification time when none of the paths
+// exist, so that polls skip until one appears rather than applying an empty
+// merge every time.
+const noFiles = -1
+
+func (c *Configuration) updateMulti() error {
+	files, err := expandPaths(c.paths)
+	if err != nil {
+		c.logf("Configuration::updateMulti error expanding paths: %v\n", err)
+		return err
+	}
+	mtimes := make(map[string]int64, len(files))
+	for _, path := range files {
+		if stat, err := os.Stat(path); err == nil {
+			mtimes[path] = stat.ModTime().UnixNano()
+		} else if !errors.Is(err, os.ErrNotExist) {
+			c.logf("Configuration::updateMulti error opening %s: %v\n", path, err)
+			return err
+		}
+	}
+	newest := int64(0)
+	for _, mtime := range mtimes {
+		newest = max(newest, mtime)
+	}
+	if c.lastupdate != 0 && maps.Equal(mtimes, c.mtimes) && !c.includesChanged() {
+		return nil
+	} else if c.settling(strings.Join(c.paths, ", "), time.Unix(0, newest)) {
+		return nil
+	}
+	if err := c.beforeReload(strings.Join(c.paths, ", ")); err != nil {
+		return err
+	}
+	merged := parsed{entries: make([]entry, 0)}
+	seen := make(map[string]struct{})
+	for _, path := range files {
+		if _, found := mtimes[path]; !found {
+			continue
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			c.logf("Configuration::updateMulti error opening %s: %v\n", path, err)
+			return err
+		}
+		result, err := c.decode("updateMulti", path, c.formatFor(path), f)
+		f.Close()
+		if err != nil {
+			c.logf("Configuration::updateMulti error reading %s: %v\n", path, err)
+			return err
+		}
+		for _, e := range result.entries {
+			seen[e.key] = struct{}{}
+		}
+		for _, e := range result.errors {
+			merged.errors = append(merged.errors, fmt.Errorf("%s: %w", path, e))
+		}
+		merged.entries = append(merged.entries, result.entries...)
+		merged.includes = append(merged.includes, result.includes...)
+		merged.summary.Comments += result.summary.Comments
+		merged.summary.Duplicates += result.summary.Duplicates
+		merged.summary.Errors += result.summary.Errors
+		merged.summary.Skipped += result.summary.Skipped
+	}
+	merged.summary.Keys = len(seen)
+	c.mtimes, c.includes = mtimes, merged.includes
+	if c.lastupdate = max(c.lastupdate, newest); c.lastupdate == 0 {
+		c.lastupdate = noFiles
+	}
+	source := strings.Join(c.paths, ", ")
+	c.summarize("updateMulti", source, merged)
+	if err := c.tolerate("updateMulti", source, merged.errors, -1); err != nil {
+		return err
+	} else if err := c.apply("updateMulti", source, merged.entries, c.PruneRemoved.Load()); err != nil {
+		return err
+	}
+	c.writeCache()
+	return nil
+}
+
+// expandPaths replaces each glob in paths with its sorted matches.
+func expandPaths(paths []string) ([]string, error) {
+	results := make([]string, 0, len(paths))
+	for _, path := range paths {
+		if !strings.ContainsAny(path, "*?[") {
+			results = append(results, path)
+			continue
+		}
+		matches, err := filepath.Glob(path)
+		if err != nil {
+			return nil, fmt.Errorf("path '%s': %w", path, err)
+		}
+		sort.Strings(matches)
+		results = append(results, matches...)
+	}
+	return results, nil
+}
@@ -0,0 +1,68 @@
+package configuration
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewMulti(t *testing.T) {
+	dir := t.TempDir()
+	base, local, missing := filepath.Join(dir, "base.conf"), filepath.Join(dir, "local.conf"), filepath.Join(dir, "missing.conf")
+	writeFile(t, base, "host=base\nport=80\n")
+	writeFile(t, local, "host=local\n")
+	config := NewMulti(context.Background(), base, local, missing)
+	defer config.Close()
+	if got := config.Get("host"); got != "local" {
+		t.Errorf("host = %q, want the later file to win", got)
+	} else if got := config.Get("port"); got != "80" {
+		t.Errorf("port = %q, want the value only in the earlier file", got)
+	}
+	if file, _, _ := config.Origin("host"); file != local {
+		t.Errorf("Origin(host) = %s, want %s", file, local)
+	}
+	writeFile(t, missing, "port=8080\n")
+	config.Update()
+	if got := config.Get("port"); got != "8080" {
+		t.Errorf("port = %q after the missing file appeared, want 8080", got)
+	}
+	writeFile(t, base, "host=base\nport=80\nextra=1\n")
+	config.Update()
+	if got := config.Get("extra"); got != "1" {
+		t.Errorf("extra = %q after editing the first file, want 1", got)
+	} else if got := config.Get("port"); got != "8080" {
+		t.Errorf("port = %q after editing the first file, want the later override kept", got)
+	}
+}
+
+func TestNewMultiGlob(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "20-local.conf"), "host=local\n")
+	writeFile(t, filepath.Join(dir, "10-base.conf"), "host=base\nport=80\n")
+	config := NewMulti(context.Background(), filepath.Join(dir, "*.conf"))
+	defer config.Close()
+	if got := config.Get("host"); got != "local" {
+		t.Errorf("host = %q, want matches merged in lexical order", got)
+	}
+	writeFile(t, filepath.Join(dir, "30-extra.conf"), "port=8080\n")
+	config.Update()
+	if got := config.Get("port"); got != "8080" {
+		t.Errorf("port = %q after a new file matched the glob, want 8080", got)
+	}
+}
+
+func TestNewMultiSkipsPollsWithNoFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing.conf")
+	config := NewMulti(context.Background(), path)
+	defer config.Close()
+	reloads := config.Status().Reloads
+	config.Update()
+	if got := config.Status().Reloads; got != reloads {
+		t.Errorf("Reloads = %d after polling with no files, want %d", got, reloads)
+	}
+	writeFile(t, path, "host=new\n")
+	config.Update()
+	if got := config.Get("host"); got != "new" {
+		t.Errorf("host = %q once the file appeared, want new", got)
+	}
+}
@@ -0,0 +1,43 @@
+package configuration
+
+import (
+	"time"
+)
+
+// FatalHandler is called by the Must accessors when a required key is
+// missing or malformed. It panics by default; services may replace it with
+// something like log.Fatal.
+var FatalHandler = func(err error) {
+	panic(err)
+}
+
+func must[T any](t T, err error) T {
+	if err != nil {
+		FatalHandler(err)
+	}
+	return t
+}
+
+func (c *Configuration) MustGet(key string) string {
+	return must(c.lookup(key))
+}
+
+func (c *Configuration) MustGetInt(key string) int {
+	return must(c.GetInt(key))
+}
+
+func (c *Configuration) MustGetInt64(key string) int64 {
+	return must(c.GetInt64(key))
+}
+
+func (c *Configuration) MustGetBool(key string) bool {
+	return must(c.GetBool(key))
+}
+
+func (c *Configuration) MustGetFloat64(key string) float64 {
+	return must(c.GetFloat64(key))
+}
+
+func (c *Configuration) MustGetDuration(key string) time.Duration {
+	return must(c.GetDuration(key))
+}
@@ -0,0 +1,36 @@
+package configuration
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMustGet(t *testing.T) {
+	config, _ := newTestConfiguration(t, "name=app\nport=8080\nbad=x\n")
+	var handled []error
+	previous := FatalHandler
+	FatalHandler = func(err error) { handled = append(handled, err) }
+	t.Cleanup(func() { FatalHandler = previous })
+	if got := config.MustGet("name"); got != "app" {
+		t.Errorf("MustGet = %q, want app", got)
+	} else if got := config.MustGetInt("port"); got != 8080 {
+		t.Errorf("MustGetInt = %d, want 8080", got)
+	} else if len(handled) != 0 {
+		t.Errorf("FatalHandler called for present keys: %v", handled)
+	}
+	config.MustGet("missing")
+	config.MustGetInt("bad")
+	if len(handled) != 2 || !errors.Is(handled[0], ErrKeyNotFound) {
+		t.Errorf("FatalHandler got %v, want a missing key and a malformed value", handled)
+	}
+}
+
+func TestMustGetPanicsByDefault(t *testing.T) {
+	config, _ := newTestConfiguration(t, "")
+	defer func() {
+		if err, ok := recover().(error); !ok || !errors.Is(err, ErrKeyNotFound) {
+			t.Errorf("MustGetDuration recovered %v, want %v", err, ErrKeyNotFound)
+		}
+	}()
+	config.MustGetDuration("missing")
+}
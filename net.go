@@ -0,0 +1,44 @@
+package configuration
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+func (c *Configuration) GetMAC(key string) (net.HardwareAddr, error) {
+	value, err := c.lookup(key)
+	if err != nil {
+		return nil, err
+	} else if mac, err := net.ParseMAC(strings.TrimSpace(value)); err != nil {
+		return nil, fmt.Errorf("key '%s': %w", key, err)
+	} else {
+		return mac, nil
+	}
+}
+
+func (c *Configuration) GetHostPort(key string) (host string, port int, err error) {
+	value, err := c.lookup(key)
+	if err != nil {
+		return "", 0, err
+	} else if host, portString, err := net.SplitHostPort(strings.TrimSpace(value)); err != nil {
+		return "", 0, fmt.Errorf("key '%s': %w", key, err)
+	} else if port, err := strconv.Atoi(portString); err != nil {
+		return "", 0, fmt.Errorf("key '%s': %w", key, err)
+	} else {
+		return host, port, nil
+	}
+}
+
+func (c *Configuration) GetInterfaces(key string) ([]net.Interface, error) {
+	results := make([]net.Interface, 0)
+	for _, name := range splitList(c.Get(key), ",") {
+		if iface, err := net.InterfaceByName(name); err != nil {
+			return nil, fmt.Errorf("key '%s': interface '%s': %w", key, name, err)
+		} else {
+			results = append(results, *iface)
+		}
+	}
+	return results, nil
+}
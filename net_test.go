@@ -0,0 +1,75 @@
+package configuration
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestGetMAC(t *testing.T) {
+	config, _ := newTestConfiguration(t, "mac=00:1a:2b:3c:4d:5e\nbad=00:1a:2b\n")
+	if mac, err := config.GetMAC("mac"); err != nil {
+		t.Errorf("GetMAC: %v", err)
+	} else if got := mac.String(); got != "00:1a:2b:3c:4d:5e" {
+		t.Errorf("GetMAC = %s, want 00:1a:2b:3c:4d:5e", got)
+	}
+	if _, err := config.GetMAC("bad"); err == nil {
+		t.Error("GetMAC accepted a malformed address")
+	} else if _, err := config.GetMAC("missing"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("GetMAC of a missing key = %v, want %v", err, ErrKeyNotFound)
+	}
+}
+
+func TestGetHostPort(t *testing.T) {
+	config, _ := newTestConfiguration(t, "v4=10.0.0.1:8080\nv6=[::1]:443\nnoport=10.0.0.1\n")
+	tests := []struct {
+		key  string
+		host string
+		port int
+	}{
+		{"v4", "10.0.0.1", 8080},
+		{"v6", "::1", 443},
+	}
+	for _, test := range tests {
+		if host, port, err := config.GetHostPort(test.key); err != nil {
+			t.Errorf("GetHostPort(%q): %v", test.key, err)
+		} else if host != test.host || port != test.port {
+			t.Errorf("GetHostPort(%q) = %s, %d, want %s, %d", test.key, host, port, test.host, test.port)
+		}
+	}
+	if _, _, err := config.GetHostPort("noport"); err == nil {
+		t.Error("GetHostPort accepted a value without a port")
+	} else if _, _, err := config.GetHostPort("missing"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("GetHostPort of a missing key = %v, want %v", err, ErrKeyNotFound)
+	}
+}
+
+func TestGetInterfaces(t *testing.T) {
+	config, _ := newTestConfiguration(t, "")
+	if got, err := config.GetInterfaces("missing"); err != nil || len(got) != 0 {
+		t.Errorf("GetInterfaces of a missing key = %v, %v, want none", got, err)
+	}
+	config.SetKeyValue("bad", "no-such-interface0")
+	if _, err := config.GetInterfaces("bad"); err == nil || !strings.Contains(err.Error(), "no-such-interface0") {
+		t.Errorf("GetInterfaces error = %v, want one naming the interface", err)
+	}
+	loopback := ""
+	if interfaces, err := net.Interfaces(); err == nil {
+		for _, iface := range interfaces {
+			if iface.Flags&net.FlagLoopback != 0 {
+				loopback = iface.Name
+				break
+			}
+		}
+	}
+	if len(loopback) == 0 {
+		t.Skip("no loopback interface")
+	}
+	config.SetKeyValue("good", loopback)
+	if got, err := config.GetInterfaces("good"); err != nil {
+		t.Errorf("GetInterfaces(%s): %v", loopback, err)
+	} else if len(got) != 1 || got[0].Name != loopback {
+		t.Errorf("GetInterfaces(%s) = %v", loopback, got)
+	}
+}
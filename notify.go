@@ -0,0 +1,96 @@
+package configuration
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// NotifyFallbackPace is how often files are still polled once WatchNotify
+// has succeeded, in case an event is missed.
+var NotifyFallbackPace = time.Minute
+
+// WatchNotify reloads as soon as the operating system reports a change to
+// a watched file, instead of waiting for the next poll. The directories
+// holding the files are watched so that editors and deployment tools that
+// replace files by renaming are noticed too. When notifications are not
+// supported the error is returned and polling continues at MaintenancePace.
+func (c *Configuration) WatchNotify() error {
+	if c.notifying.Load() {
+		return nil
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		c.logf("Configuration::WatchNotify falling back to polling: %v\n", err)
+		return err
+	}
+	watched := make(map[string]struct{})
+	if err := c.watchDirectories(watcher, watched); err != nil {
+		watcher.Close()
+		c.logf("Configuration::WatchNotify falling back to polling: %v\n", err)
+		return err
+	}
+	if !c.notifying.CompareAndSwap(false, true) {
+		watcher.Close()
+		return nil
+	}
+	go func() {
+		defer watcher.Close()
+		defer c.notifying.Store(false)
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				} else if event.Has(fsnotify.Chmod) && !event.Has(fsnotify.Write) {
+					continue
+				}
+				c.wakeUp()
+				c.watchDirectories(watcher, watched)
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				c.logf("Configuration::WatchNotify error: %v\n", err)
+			case <-c.done:
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// watchDirectories adds the directory of every file c reads, including
+// fragments pulled in by include directives, that is not yet watched.
+func (c *Configuration) watchDirectories(watcher *fsnotify.Watcher, watched map[string]struct{}) error {
+	c.mutex.RLock()
+	files := append([]string{c.filename}, c.paths...)
+	for _, dep := range c.includes {
+		files = append(files, dep.pattern)
+	}
+	c.mutex.RUnlock()
+	for _, file := range files {
+		if len(file) == 0 {
+			continue
+		}
+		dir := filepath.Dir(file)
+		if _, found := watched[dir]; found || strings.ContainsAny(dir, "*?[") {
+			continue
+		} else if err := watcher.Add(dir); err != nil {
+			return err
+		}
+		watched[dir] = struct{}{}
+	}
+	return nil
+}
+
+// wakeUp asks the maintenance goroutine to poll now rather than at its
+// next scheduled time.
+func (c *Configuration) wakeUp() {
+	select {
+	case c.wake <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,23 @@
+package configuration
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWatchNotify(t *testing.T) {
+	config, path := newTestConfiguration(t, "key=old\n")
+	if err := config.WatchNotify(); err != nil {
+		t.Skipf("file notifications unavailable: %v", err)
+	} else if err := config.WatchNotify(); err != nil {
+		t.Errorf("a second WatchNotify = %v, want nil", err)
+	}
+	writeFile(t, path, "key=new\n")
+	deadline := time.Now().Add(5 * time.Second)
+	for config.Get("key") != "new" && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := config.Get("key"); got != "new" {
+		t.Errorf("key = %q after the write, want new without waiting for a poll", got)
+	}
+}
@@ -0,0 +1,141 @@
+package configuration
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Logger receives the package's log output; *log.Logger satisfies it.
+type Logger interface {
+	Printf(format string, v ...any)
+}
+
+// Option configures a Configuration built by NewWithOptions before its
+// first load.
+type Option func(c *Configuration)
+
+// WithPollInterval sets how often this Configuration polls its file,
+// in place of MaintenancePace.
+func WithPollInterval(pace time.Duration) Option {
+	return func(c *Configuration) {
+		c.pace.Store(int64(pace))
+	}
+}
+
+func WithJitter(fraction float64) Option {
+	return func(c *Configuration) {
+		c.SetJitter(fraction)
+	}
+}
+
+// WithLogging turns the logging of individual updates on or off, in place
+// of DefaultShouldLog.
+func WithLogging(shouldLog bool) Option {
+	return func(c *Configuration) {
+		c.ShouldLogUpdates.Store(shouldLog)
+	}
+}
+
+// WithLogger sends log output to logger instead of the standard logger.
+func WithLogger(logger Logger) Option {
+	return func(c *Configuration) {
+		c.logger = logger
+	}
+}
+
+func WithFormat(format Format) Option {
+	return func(c *Configuration) {
+		c.format, c.formatOverride = format, true
+	}
+}
+
+// WithParser parses the file with p regardless of its extension.
+func WithParser(p Parser) Option {
+	return func(c *Configuration) {
+		c.parser = p
+	}
+}
+
+func WithDelimiters(delimiters string) Option {
+	return func(c *Configuration) {
+		c.SetDelimiters(delimiters)
+	}
+}
+
+func WithEnvOverlay(prefix string) Option {
+	return func(c *Configuration) {
+		c.envOverlay, c.envPrefix = true, prefix
+	}
+}
+
+func WithProfile(profile string) Option {
+	return func(c *Configuration) {
+		c.profile = profile
+	}
+}
+
+func WithDefaults(defaults map[string]string) Option {
+	return func(c *Configuration) {
+		if c.defaults == nil {
+			c.defaults = make(map[string]string, len(defaults))
+		}
+		for key, value := range defaults {
+			c.defaults[key] = value
+		}
+	}
+}
+
+func WithValidator(v Validator) Option {
+	return func(c *Configuration) {
+		c.validators = append(c.validators, v)
+	}
+}
+
+func WithFollowSymlinks() Option {
+	return func(c *Configuration) {
+		c.FollowSymlinks.Store(true)
+	}
+}
+
+func WithHashContents() Option {
+	return func(c *Configuration) {
+		c.HashContents.Store(true)
+	}
+}
+
+func WithPruneRemoved() Option {
+	return func(c *Configuration) {
+		c.PruneRemoved.Store(true)
+	}
+}
+
+func WithErrorThreshold(n int) Option {
+	return func(c *Configuration) {
+		c.SetErrorThreshold(n)
+	}
+}
+
+// NewWithOptions builds a Configuration for filename and starts watching
+// it once every option has been applied, so the options are honored from
+// the very first load.
+func NewWithOptions(ctx context.Context, filename string, opts ...Option) *Configuration {
+	config := &Configuration{
+		filename:   filename,
+		parameters: make(map[string]string),
+	}
+	config.ShouldLogUpdates.Store(DefaultShouldLog)
+	for _, opt := range opts {
+		opt(config)
+	}
+	config.start(ctx)
+	return config
+}
+
+func (c *Configuration) logf(format string, v ...any) {
+	if c.logger != nil {
+		c.logger.Printf(format, v...)
+	} else {
+		log.Printf(format, v...)
+	}
+}
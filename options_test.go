@@ -0,0 +1,62 @@
+package configuration
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestNewWithOptionsAppliesBeforeFirstLoad(t *testing.T) {
+	t.Setenv("OPTIONSTEST_PORT", "9090")
+	logger := &testLogger{}
+	config, _ := newTestConfiguration(t, "url=http://example.com:80\nport=8080\nprod.region=eu\n",
+		WithLogger(logger),
+		WithLogging(true),
+		WithDelimiters("="),
+		WithEnvOverlay("OPTIONSTEST"),
+		WithProfile("prod"),
+		WithDefaults(map[string]string{"region": "us", "workers": "4"}),
+	)
+	want := map[string]string{"url": "http://example.com:80", "port": "9090", "region": "eu", "workers": "4"}
+	for key, value := range want {
+		if got := config.Get(key); got != value {
+			t.Errorf("%s = %q, want %q", key, got, value)
+		}
+	}
+	if !logger.contains("storing key 'url'") {
+		t.Errorf("the first load was not logged to the WithLogger logger: %q", logger.lines)
+	}
+}
+
+func TestWithParser(t *testing.T) {
+	parser := ParserFunc(func(r io.Reader) (map[string]string, error) {
+		data, err := io.ReadAll(r)
+		return map[string]string{"raw": string(data)}, err
+	})
+	config, _ := newTestConfiguration(t, "a=1", WithParser(parser))
+	if got := config.Get("raw"); got != "a=1" {
+		t.Errorf("raw = %q, want the custom parser to read the whole file", got)
+	} else if _, found := config.Lookup("a"); found {
+		t.Error("the key=value parser ran despite WithParser")
+	}
+}
+
+func TestWithValidator(t *testing.T) {
+	errRejected := errors.New("rejected")
+	config, _ := newTestConfiguration(t, "key=value\n", WithValidator(func(map[string]string) error { return errRejected }))
+	if _, found := config.Lookup("key"); found {
+		t.Error("the first load skipped the WithValidator validator")
+	}
+}
+
+func TestWithPollInterval(t *testing.T) {
+	config, _ := newTestConfiguration(t, "", WithPollInterval(time.Minute))
+	if got := config.nextPace(); got != time.Minute {
+		t.Errorf("nextPace = %v, want the per-instance minute", got)
+	}
+	jittered, _ := newTestConfiguration(t, "", WithPollInterval(time.Minute), WithJitter(0.5))
+	if got := jittered.nextPace(); got < 30*time.Second || got > 90*time.Second {
+		t.Errorf("nextPace = %v, want a minute +/- 50%%", got)
+	}
+}
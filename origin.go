@@ -0,0 +1,16 @@
+package configuration
+
+type origin struct {
+	file string
+	line int
+}
+
+// Origin reports the file and line that last supplied key. Keys set at
+// runtime through SetKeyValue or a Transaction have no origin.
+func (c *Configuration) Origin(key string) (file string, line int, ok bool) {
+	c = c.origin()
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	o, ok := c.origins[key]
+	return o.file, o.line, ok
+}
@@ -0,0 +1,27 @@
+package configuration
+
+import (
+	"testing"
+)
+
+func TestOrigin(t *testing.T) {
+	config, path := newTestConfiguration(t, "a=1\n\nb=2\n")
+	tests := []struct {
+		key  string
+		line int
+	}{
+		{"a", 1},
+		{"b", 3},
+	}
+	for _, test := range tests {
+		if file, line, ok := config.Origin(test.key); !ok {
+			t.Errorf("Origin(%q) not found", test.key)
+		} else if file != path || line != test.line {
+			t.Errorf("Origin(%q) = %s:%d, want %s:%d", test.key, file, line, path, test.line)
+		}
+	}
+	config.SetKeyValue("a", "runtime")
+	if _, _, ok := config.Origin("a"); ok {
+		t.Error("a key set at runtime still reports an origin")
+	}
+}
@@ -0,0 +1,262 @@
+package configuration
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type entry struct {
+	key, value string
+	file       string
+	line       int
+}
+
+type parsed struct {
+	entries  []entry
+	errors   []error
+	includes []dependency
+	summary  ParseSummary
+}
+
+type ParseSummary struct {
+	Keys       int
+	Comments   int
+	Duplicates int
+	Errors     int
+	// Skipped counts lines dropped by StrictKeys. They are not errors and
+	// never count against the error threshold.
+	Skipped int
+}
+
+func (s ParseSummary) String() string {
+	summary := fmt.Sprintf("loaded %d keys, %d comments, %d duplicates, %d errors", s.Keys, s.Comments, s.Duplicates, s.Errors)
+	if s.Skipped > 0 {
+		summary += fmt.Sprintf(", %d skipped", s.Skipped)
+	}
+	return summary
+}
+
+func isComment(line string) bool {
+	line = strings.TrimSpace(line)
+	return strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";")
+}
+
+// stripComment drops a trailing "# ..." comment from value. The # must
+// follow whitespace and come after the value proper, and a value that opens
+// with a quote is only searched past its closing quote, so values like
+// color=#fff and "a # b" are left intact.
+func stripComment(value string) string {
+	start := len(value) - len(strings.TrimLeft(value, " \t"))
+	i := start
+	if i < len(value) && (value[i] == '"' || value[i] == '\'') {
+		if end := closingQuote(value, i); end == -1 {
+			return value
+		} else {
+			i = end + 1
+		}
+	}
+	for ; i < len(value); i++ {
+		if value[i] == '#' && i > start && (value[i-1] == ' ' || value[i-1] == '\t') {
+			return strings.TrimRight(value[:i], " \t")
+		}
+	}
+	return value
+}
+
+// unquote removes the quotes around a value wrapped in single or double
+// quotes. Single-quoted values are literal; double-quoted values expand
+// \n, \t, \r, \" and \\. Anything else is returned unchanged.
+func unquote(value string) string {
+	trimmed := strings.TrimSpace(value)
+	if len(trimmed) < 2 || (trimmed[0] != '"' && trimmed[0] != '\'') || closingQuote(trimmed, 0) != len(trimmed)-1 {
+		return value
+	} else if trimmed[0] == '\'' {
+		return trimmed[1 : len(trimmed)-1]
+	}
+	var builder strings.Builder
+	for i := 1; i < len(trimmed)-1; i++ {
+		if trimmed[i] != '\\' {
+			builder.WriteByte(trimmed[i])
+			continue
+		}
+		switch i++; trimmed[i] {
+		case 'n':
+			builder.WriteByte('\n')
+		case 't':
+			builder.WriteByte('\t')
+		case 'r':
+			builder.WriteByte('\r')
+		case '"', '\\':
+			builder.WriteByte(trimmed[i])
+		default:
+			builder.WriteByte('\\')
+			builder.WriteByte(trimmed[i])
+		}
+	}
+	return builder.String()
+}
+
+// closingQuote returns the index of the quote closing the one at open, or
+// -1. Double quotes honor backslash escapes; single quotes are literal.
+func closingQuote(value string, open int) int {
+	for i := open + 1; i < len(value); i++ {
+		if value[open] == '"' && value[i] == '\\' {
+			i++
+		} else if value[i] == value[open] {
+			return i
+		}
+	}
+	return -1
+}
+
+// sectionHeader recognizes INI-style [section] lines. Keys that follow are
+// stored as section.key until the next header; an empty [] header returns
+// to unprefixed keys.
+func sectionHeader(line string) (string, bool) {
+	if line = strings.TrimSpace(line); len(line) < 2 || line[0] != '[' || line[len(line)-1] != ']' {
+		return "", false
+	}
+	return strings.TrimSpace(line[1 : len(line)-1]), true
+}
+
+func (c *Configuration) parse(caller, filename string, r io.Reader) (parsed, error) {
+	seen := make(map[string]struct{})
+	result, err := c.parseFile(caller, filename, r, seen, nil)
+	result.summary.Keys = len(seen)
+	result.summary.Errors = len(result.errors)
+	return result, err
+}
+
+func (c *Configuration) parseFile(caller, filename string, r io.Reader, seen map[string]struct{}, stack []string) (parsed, error) {
+	result := parsed{entries: make([]entry, 0)}
+	section, delimiters := "", c.Delimiters()
+	fail := func(line int, text string, err error) {
+		result.errors = append(result.errors, fmt.Errorf("line %d: %w", line, err))
+		if c.ShouldLogUpdates.Load() {
+			c.logf("Configuration::%s error parsing %s: %v\n", caller, escape(text), err)
+		}
+	}
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		start, text := line, scanner.Text()
+		if isComment(text) {
+			result.summary.Comments++
+			continue
+		} else if name, found := sectionHeader(text); found {
+			section = name
+			continue
+		} else if pattern, found := includeDirective(text, delimiters); found {
+			if err := c.include(&result, caller, filename, pattern, seen, stack); err != nil {
+				fail(start, text, err)
+			}
+			continue
+		}
+		for continued(text) && scanner.Scan() {
+			line++
+			text = text[:len(text)-1] + strings.TrimLeft(scanner.Text(), " \t")
+		}
+		split, err := splitLine(text, delimiters)
+		if err != nil {
+			if !errors.Is(err, ErrEmptyParameter) {
+				fail(start, text, err)
+			}
+			continue
+		} else if !c.PreserveWhitespace.Load() {
+			split[0], split[1] = strings.TrimSpace(split[0]), strings.TrimSpace(split[1])
+		}
+		if marker, found := heredoc(split[1]); found {
+			lines, terminated := make([]string, 0), false
+			for scanner.Scan() {
+				if line++; strings.TrimSpace(scanner.Text()) == marker {
+					terminated = true
+					break
+				}
+				lines = append(lines, scanner.Text())
+			}
+			if !terminated {
+				fail(start, text, fmt.Errorf("missing heredoc terminator %s", marker))
+				continue
+			}
+			split[1] = strings.Join(lines, "\n")
+		} else {
+			if c.StrictDelimiter.Load() && strings.IndexAny(split[1], delimiters) == 0 {
+				c.logf("Configuration::%s line %d '%s' has a value starting with a delimiter, possible typo\n", caller, start, escape(text))
+			}
+			split[1] = unquote(stripComment(split[1]))
+		}
+		if c.StrictKeys.Load() {
+			if key, err := strictKey(split[0]); err != nil {
+				result.summary.Skipped++
+				c.logf("Configuration::%s skipping line %d: %v\n", caller, start, err)
+				continue
+			} else {
+				split[0] = key
+			}
+		}
+		if len(section) > 0 {
+			split[0] = section + "." + split[0]
+		}
+		if _, found := seen[split[0]]; found {
+			result.summary.Duplicates++
+		} else {
+			seen[split[0]] = struct{}{}
+		}
+		result.entries = append(result.entries, entry{key: split[0], value: split[1], file: filename, line: start})
+	}
+	return result, scanner.Err()
+}
+
+// SetDelimiters restricts or replaces the characters that separate keys
+// from values in key=value files, e.g. "=" so that values may contain ':'.
+// An empty string restores DefaultDelimiters.
+func (c *Configuration) SetDelimiters(delimiters string) {
+	if len(delimiters) == 0 {
+		delimiters = DefaultDelimiters
+	}
+	if previous, _ := c.delimiters.Swap(delimiters).(string); previous != delimiters {
+		c.mutex.Lock()
+		c.lastupdate = 0
+		c.mutex.Unlock()
+	}
+}
+
+func (c *Configuration) Delimiters() string {
+	if delimiters, ok := c.delimiters.Load().(string); ok {
+		return delimiters
+	}
+	return DefaultDelimiters
+}
+
+// continued reports whether line ends in an unescaped backslash, joining it
+// with the next line.
+func continued(line string) bool {
+	trailing := len(line) - len(strings.TrimRight(line, `\`))
+	return trailing%2 == 1
+}
+
+// heredoc recognizes a value of the form <<MARKER, whose content is every
+// following line up to one consisting of MARKER alone.
+func heredoc(value string) (string, bool) {
+	marker, found := strings.CutPrefix(strings.TrimSpace(value), "<<")
+	if !found || len(marker) == 0 || strings.ContainsAny(marker, " \t\"'") {
+		return "", false
+	}
+	return marker, true
+}
+
+func (c *Configuration) summarize(caller, source string, result parsed) {
+	c.summary = result.summary
+	if c.ShouldLogUpdates.Load() {
+		c.logf("Configuration::%s %s: %v\n", caller, source, result.summary)
+	}
+}
+
+func (c *Configuration) LastParseSummary() ParseSummary {
+	c = c.origin()
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.summary
+}
@@ -0,0 +1,168 @@
+package configuration
+
+import (
+	"testing"
+)
+
+func TestStrictDelimiter(t *testing.T) {
+	for _, line := range []string{"a==b", "a=:b"} {
+		for _, strict := range []bool{false, true} {
+			logger := &testLogger{}
+			config, _ := newTestConfiguration(t, line+"\n", WithLogger(logger))
+			config.StrictDelimiter.Store(strict)
+			config.Reload()
+			if warned := logger.contains("possible typo"); warned != strict {
+				t.Errorf("%s with StrictDelimiter %v: warned = %v", line, strict, warned)
+			}
+			if got, want := config.Get("a"), line[2:]; got != want {
+				t.Errorf("%s: a = %q, want %q", line, got, want)
+			}
+		}
+	}
+}
+
+func TestLastParseSummary(t *testing.T) {
+	config, _ := newTestConfiguration(t, "# header\na=1\n\n; section\nb=2\nc=3\na=4\n  # indented\nnot a pair\n", WithErrorThreshold(-1))
+	want := ParseSummary{Keys: 3, Comments: 3, Duplicates: 1, Errors: 1}
+	if got := config.LastParseSummary(); got != want {
+		t.Errorf("LastParseSummary = %+v, want %+v", got, want)
+	}
+	if got := config.Get("a"); got != "4" {
+		t.Errorf("a = %q, want the last duplicate 4", got)
+	}
+}
+
+func TestSectionHeaders(t *testing.T) {
+	config, _ := newTestConfiguration(t, "top=1\n[db]\nhost=localhost\n[ cache ]\nsize=10\n[]\nbottom=2\n")
+	want := map[string]string{"top": "1", "db.host": "localhost", "cache.size": "10", "bottom": "2"}
+	for key, value := range want {
+		if got, found := config.Lookup(key); !found || got != value {
+			t.Errorf("%s = %q, %v, want %q", key, got, found, value)
+		}
+	}
+	if _, found := config.Lookup("host"); found {
+		t.Error("a sectioned key was also stored unprefixed")
+	}
+}
+
+func TestStripComment(t *testing.T) {
+	tests := []struct {
+		value, want string
+	}{
+		{"value # comment", "value"},
+		{"value\t# comment", "value"},
+		{"#fff", "#fff"},
+		{" #fff", " #fff"},
+		{"a#b", "a#b"},
+		{`"a # b" # comment`, `"a # b"`},
+		{`"a \" # b" # comment`, `"a \" # b"`},
+		{`'a # b`, `'a # b`},
+		{"", ""},
+	}
+	for _, test := range tests {
+		if got := stripComment(test.value); got != test.want {
+			t.Errorf("stripComment(%q) = %q, want %q", test.value, got, test.want)
+		}
+	}
+	config, _ := newTestConfiguration(t, "color=#fff\nport=8080 # the default\n")
+	if got := config.Get("color"); got != "#fff" {
+		t.Errorf("color = %q, want #fff", got)
+	} else if got := config.Get("port"); got != "8080" {
+		t.Errorf("port = %q, want the comment stripped", got)
+	}
+}
+
+func TestUnquote(t *testing.T) {
+	tests := []struct {
+		value, want string
+	}{
+		{`"a b"`, "a b"},
+		{` "padded" `, "padded"},
+		{`"line1\nline2\ttab\r"`, "line1\nline2\ttab\r"},
+		{`"say \"hi\" \\ done"`, `say "hi" \ done`},
+		{`"keep \x"`, `keep \x`},
+		{`'literal \n'`, `literal \n`},
+		{`"unterminated`, `"unterminated`},
+		{`"a" "b"`, `"a" "b"`},
+		{`plain`, `plain`},
+	}
+	for _, test := range tests {
+		if got := unquote(test.value); got != test.want {
+			t.Errorf("unquote(%q) = %q, want %q", test.value, got, test.want)
+		}
+	}
+	config, _ := newTestConfiguration(t, "greeting=\"  hello # world  \" # comment\n")
+	if got := config.Get("greeting"); got != "  hello # world  " {
+		t.Errorf("greeting = %q, want the quoted value with its spaces and #", got)
+	}
+}
+
+func TestContinuationsAndHeredocs(t *testing.T) {
+	config, _ := newTestConfiguration(t, `hosts=a,\
+    b,\
+    c
+path=C:\\
+cert=<<PEM
+-----BEGIN-----
+  indented
+-----END-----
+PEM
+after=1
+broken=<<END
+never terminated
+`, WithErrorThreshold(-1))
+	want := map[string]string{
+		"hosts": "a,b,c",
+		"path":  `C:\\`,
+		"cert":  "-----BEGIN-----\n  indented\n-----END-----",
+		"after": "1",
+	}
+	for key, value := range want {
+		if got, found := config.Lookup(key); !found || got != value {
+			t.Errorf("%s = %q, %v, want %q", key, got, found, value)
+		}
+	}
+	if _, found := config.Lookup("broken"); found {
+		t.Error("an unterminated heredoc was stored")
+	}
+	if _, line, _ := config.Origin("after"); line != 10 {
+		t.Errorf("Origin(after) line = %d, want 10", line)
+	}
+}
+
+func TestSetDelimiters(t *testing.T) {
+	config, path := newTestConfiguration(t, "", WithErrorThreshold(-1))
+	config.SetDelimiters("=")
+	writeFile(t, path, "url=http://example.com:8080\ntime:12:00\n")
+	config.Update()
+	if got := config.Get("url"); got != "http://example.com:8080" {
+		t.Errorf("url = %q with '=' as the only delimiter", got)
+	}
+	if _, found := config.Lookup("time"); found {
+		t.Error("':' split a line although it is no longer a delimiter")
+	} else if summary := config.LastParseSummary(); summary.Errors != 1 {
+		t.Errorf("summary = %+v, want the ':' line reported as an error", summary)
+	}
+	config.SetDelimiters("")
+	if got := config.Delimiters(); got != DefaultDelimiters {
+		t.Errorf("Delimiters = %q after resetting, want %q", got, DefaultDelimiters)
+	}
+	config.Update()
+	if got := config.Get("time"); got != "12:00" {
+		t.Errorf("time = %q after restoring the default delimiters, want 12:00", got)
+	}
+}
+
+func TestPreserveWhitespace(t *testing.T) {
+	config, _ := newTestConfiguration(t, " foo = bar \n")
+	if got, found := config.Lookup("foo"); !found || got != "bar" {
+		t.Errorf("foo = %q, %v, want bar trimmed by default", got, found)
+	}
+	preserved, _ := newTestConfiguration(t, "")
+	preserved.PreserveWhitespace.Store(true)
+	writeFile(t, filenameOf(preserved), "foo = bar\n")
+	preserved.Update()
+	if got, found := preserved.Lookup("foo "); !found || got != " bar" {
+		t.Errorf("'foo ' = %q, %v, want ' bar' with PreserveWhitespace", got, found)
+	}
+}
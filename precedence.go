@@ -0,0 +1,100 @@
+package configuration
+
+// Values are resolved from four layers, lowest first: defaults registered
+// with SetDefault, the configuration file(s), the environment overlay, and
+// runtime overrides made through SetKeyValue or a Transaction. Reloads only
+// ever replace the file and environment layers, so a runtime override
+// survives until ClearOverride is called.
+
+// SetDefault supplies the value used for key when no file, environment
+// variable or runtime override provides one.
+func (c *Configuration) SetDefault(key, value string) {
+	c.mutex.Lock()
+	defer c.release()
+	if c.defaults == nil {
+		c.defaults = make(map[string]string)
+	}
+	c.defaults[key] = value
+	_, loaded := c.loaded[key]
+	_, overridden := c.overrides[key]
+	if !loaded && !overridden {
+		c.store("SetDefault", key, value)
+	}
+}
+
+// ClearOverride drops the runtime override for key, restoring the value
+// last loaded from a file or the environment, or else its default.
+func (c *Configuration) ClearOverride(key string) {
+	c.mutex.Lock()
+	defer c.release()
+	if _, found := c.overrides[key]; !found {
+		return
+	}
+	delete(c.overrides, key)
+	if value, found := c.loaded[key]; found {
+		c.store("ClearOverride", key, value)
+		if o := c.loadedOrigins[key]; len(o.file) > 0 && c.origins != nil {
+			c.origins[key] = o
+		}
+	} else if value, found := c.defaults[key]; found {
+		c.store("ClearOverride", key, value)
+	} else {
+		c.remove("ClearOverride", key)
+	}
+}
+
+func (c *Configuration) override(key string) {
+	if c.overrides == nil {
+		c.overrides = make(map[string]struct{})
+	}
+	c.overrides[key] = struct{}{}
+}
+
+// withDefaults prepends an entry for every default whose key the sources
+// have not supplied, returning how many it added.
+func (c *Configuration) withDefaults(entries []entry, replace bool) ([]entry, int) {
+	if len(c.defaults) == 0 {
+		return entries, 0
+	}
+	supplied := make(map[string]struct{}, len(entries))
+	for _, e := range entries {
+		supplied[e.key] = struct{}{}
+	}
+	results := make([]entry, 0, len(c.defaults)+len(entries))
+	for _, key := range sortedKeys(c.defaults) {
+		_, found := supplied[key]
+		if _, loaded := c.loaded[key]; !found && (replace || !loaded) {
+			results = append(results, entry{key: key, value: c.defaults[key]})
+		}
+	}
+	return append(results, entries...), len(results)
+}
+
+// remember records the values supplied by the file and environment layers,
+// for ClearOverride to fall back to. The first defaults entries came from
+// SetDefault and count only where the environment overlay replaced them.
+func (c *Configuration) remember(entries []entry, defaults int, replace bool) {
+	if c.loaded == nil || replace {
+		c.loaded, c.loadedOrigins = make(map[string]string), make(map[string]origin)
+	}
+	for i, e := range entries {
+		if i >= defaults || len(e.file) > 0 {
+			c.loaded[e.key], c.loadedOrigins[e.key] = e.value, origin{file: e.file, line: e.line}
+		}
+	}
+}
+
+func (c *Configuration) overridden(caller string, entries []entry) []entry {
+	if len(c.overrides) == 0 {
+		return entries
+	}
+	results := make([]entry, 0, len(entries))
+	for _, e := range entries {
+		if _, overridden := c.overrides[e.key]; !overridden {
+			results = append(results, e)
+		} else if stored := c.parameters[e.key]; stored != e.value && c.ShouldLogUpdates.Load() {
+			c.logf("Configuration::%s keeping runtime override of key '%s' at '%s' over '%s'\n", caller, escape(e.key), escape(stored), escape(e.value))
+		}
+	}
+	return results
+}
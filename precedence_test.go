@@ -0,0 +1,44 @@
+package configuration
+
+import (
+	"testing"
+)
+
+func TestPrecedence(t *testing.T) {
+	t.Setenv("PRECEDENCETEST_PORT", "9090")
+	config, path := newTestConfiguration(t, "host=file\n")
+	config.SetDefault("host", "default")
+	config.SetDefault("port", "80")
+	config.SetDefault("region", "us")
+	if got := config.Get("host"); got != "file" {
+		t.Errorf("host = %q, want the file over the default", got)
+	} else if got := config.Get("region"); got != "us" {
+		t.Errorf("region = %q, want the default", got)
+	}
+	config.SetEnvOverlay("PRECEDENCETEST")
+	writeFile(t, path, "host=file\nport=8080\n")
+	config.Update()
+	if got := config.Get("port"); got != "9090" {
+		t.Errorf("port = %q, want the environment over the file", got)
+	}
+	config.SetKeyValue("host", "runtime")
+	writeFile(t, path, "host=edited\nport=8080\n")
+	config.Update()
+	if got := config.Get("host"); got != "runtime" {
+		t.Errorf("host = %q after a reload, want the runtime override kept", got)
+	}
+	config.ClearOverride("host")
+	if got := config.Get("host"); got != "edited" {
+		t.Errorf("host = %q after ClearOverride, want the file value", got)
+	}
+	config.SetKeyValue("region", "eu")
+	config.ClearOverride("region")
+	if got := config.Get("region"); got != "us" {
+		t.Errorf("region = %q after ClearOverride, want the default", got)
+	}
+	config.SetKeyValue("scratch", "1")
+	config.ClearOverride("scratch")
+	if _, found := config.Lookup("scratch"); found {
+		t.Error("ClearOverride kept a key with no other layer")
+	}
+}
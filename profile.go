@@ -0,0 +1,53 @@
+package configuration
+
+import (
+	"os"
+	"strings"
+)
+
+// ProfileEnv names the environment variable that selects the active
+// profile when none is set with SetProfile.
+var ProfileEnv = "CONFIGURATION_PROFILE"
+
+// SetProfile activates profile: a key such as prod.db_host, or db_host in
+// a [prod] section, then overrides the shared db_host. Unprefixed keys
+// remain the defaults for every profile. The profile applies from the next
+// reload; an empty profile disables profiles.
+func (c *Configuration) SetProfile(profile string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.profile != profile {
+		c.profile = profile
+		c.lastupdate = 0
+	}
+}
+
+func (c *Configuration) Profile() string {
+	c = c.origin()
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.profile
+}
+
+// profiled appends an unprefixed copy of every entry in the active profile,
+// so that it is stored after, and so overrides, the shared value.
+func (c *Configuration) profiled(entries []entry) []entry {
+	if len(c.profile) == 0 {
+		return entries
+	}
+	prefix := c.profile + "."
+	for _, e := range entries {
+		if key, found := strings.CutPrefix(e.key, prefix); found && len(key) > 0 {
+			e.key = key
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+func profileFromEnv() string {
+	if len(ProfileEnv) == 0 {
+		return ""
+	}
+	return os.Getenv(ProfileEnv)
+}
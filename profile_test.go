@@ -0,0 +1,36 @@
+package configuration
+
+import (
+	"testing"
+)
+
+func TestSetProfile(t *testing.T) {
+	config, _ := newTestConfiguration(t, "db_host=shared\nport=80\nprod.db_host=prod-db\n[staging]\ndb_host=staging-db\n")
+	if got := config.Get("db_host"); got != "shared" {
+		t.Errorf("db_host = %q without a profile, want shared", got)
+	}
+	config.SetProfile("prod")
+	config.Update()
+	if got := config.Get("db_host"); got != "prod-db" {
+		t.Errorf("db_host = %q in prod, want prod-db", got)
+	} else if got := config.Get("port"); got != "80" {
+		t.Errorf("port = %q in prod, want the shared default", got)
+	}
+	config.SetProfile("staging")
+	config.Update()
+	if got := config.Get("db_host"); got != "staging-db" {
+		t.Errorf("db_host = %q in staging, want the [staging] section", got)
+	} else if got := config.Profile(); got != "staging" {
+		t.Errorf("Profile = %q, want staging", got)
+	}
+}
+
+func TestProfileFromEnv(t *testing.T) {
+	t.Setenv(ProfileEnv, "prod")
+	config, _ := newTestConfiguration(t, "db_host=shared\nprod.db_host=prod-db\n")
+	if got := config.Profile(); got != "prod" {
+		t.Errorf("Profile = %q, want prod from %s", got, ProfileEnv)
+	} else if got := config.Get("db_host"); got != "prod-db" {
+		t.Errorf("db_host = %q, want prod-db", got)
+	}
+}
@@ -0,0 +1,40 @@
+package configuration
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// ReloadOnSignal forces a Reload whenever one of signals arrives, SIGHUP
+// if none are given. Polling and WatchNotify carry on as before. The
+// returned stop function removes the handler; it is also removed when the
+// Configuration's context ends.
+func (c *Configuration) ReloadOnSignal(signals ...os.Signal) (stop func()) {
+	if len(signals) == 0 {
+		signals = []os.Signal{syscall.SIGHUP}
+	}
+	received, stopped := make(chan os.Signal, 1), make(chan struct{})
+	signal.Notify(received, signals...)
+	go func() {
+		defer signal.Stop(received)
+		for {
+			select {
+			case s := <-received:
+				if c.ShouldLogUpdates.Load() {
+					c.logf("Configuration::ReloadOnSignal received %v, reloading\n", s)
+				}
+				c.Reload()
+			case <-stopped:
+				return
+			case <-c.done:
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(stopped) })
+	}
+}
@@ -0,0 +1,26 @@
+package configuration
+
+import (
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestReloadOnSignal(t *testing.T) {
+	config, path := newTestConfiguration(t, "key=old\n")
+	stop := config.ReloadOnSignal(syscall.SIGUSR1)
+	defer stop()
+	writeFile(t, path, "key=new\n")
+	if err := syscall.Kill(os.Getpid(), syscall.SIGUSR1); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for config.Get("key") != "new" && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := config.Get("key"); got != "new" {
+		t.Errorf("key = %q after SIGUSR1, want new without waiting for a poll", got)
+	}
+	stop()
+}
@@ -0,0 +1,163 @@
+package configuration
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Source supplies one layer of parameters to a layered Configuration. Load
+// is called again on every reload.
+type Source interface {
+	Load() (map[string]string, error)
+}
+
+type FileSource string
+
+func (s FileSource) Load() (map[string]string, error) {
+	f, err := os.Open(string(s))
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]string{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	result, err := (&Configuration{}).decode("FileSource", string(s), DetectFormat(string(s)), f)
+	if err != nil {
+		return nil, err
+	}
+	results := make(map[string]string, len(result.entries))
+	for _, e := range result.entries {
+		results[e.key] = e.value
+	}
+	return results, nil
+}
+
+// EnvSource loads every environment variable starting with its prefix,
+// keyed by the lowercased remainder of the name: with prefix "MYAPP_",
+// MYAPP_DB_HOST becomes db_host.
+type EnvSource string
+
+func (s EnvSource) Load() (map[string]string, error) {
+	results := make(map[string]string)
+	for _, variable := range os.Environ() {
+		if name, value, found := strings.Cut(variable, "="); found && strings.HasPrefix(name, string(s)) && len(name) > len(s) {
+			results[strings.ToLower(name[len(s):])] = value
+		}
+	}
+	return results, nil
+}
+
+// FlagSource loads the flags that were explicitly set on the command line.
+type FlagSource struct {
+	FlagSet *flag.FlagSet
+}
+
+func (s FlagSource) Load() (map[string]string, error) {
+	results := make(map[string]string)
+	if s.FlagSet == nil {
+		return results, nil
+	} else if !s.FlagSet.Parsed() {
+		return nil, fmt.Errorf("flag set %s has not been parsed", s.FlagSet.Name())
+	}
+	s.FlagSet.Visit(func(f *flag.Flag) {
+		results[f.Name] = f.Value.String()
+	})
+	return results, nil
+}
+
+// BindFlags makes the flags explicitly set on fs the highest layer, as if
+// each had been passed to SetKeyValue, and fills every other flag from the
+// key of the same name so that the file supplies the defaults. fs must
+// already be parsed.
+func (c *Configuration) BindFlags(fs *flag.FlagSet) error {
+	if !fs.Parsed() {
+		return fmt.Errorf("flag set %s has not been parsed", fs.Name())
+	}
+	set := make(map[string]struct{})
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = struct{}{}
+	})
+	errs := make([]error, 0)
+	c.mutex.Lock()
+	fs.VisitAll(func(f *flag.Flag) {
+		if _, found := set[f.Name]; found {
+			delete(c.origins, f.Name)
+			c.override(f.Name)
+			c.store("BindFlags", f.Name, f.Value.String())
+		} else if value, found := c.parameters[f.Name]; found {
+			if err := f.Value.Set(value); err != nil {
+				errs = append(errs, fmt.Errorf("flag '%s': %w", f.Name, err))
+			}
+		}
+	})
+	c.release()
+	return errors.Join(errs...)
+}
+
+type MapSource map[string]string
+
+func (s MapSource) Load() (map[string]string, error) {
+	results := make(map[string]string, len(s))
+	for key, value := range s {
+		results[key] = value
+	}
+	return results, nil
+}
+
+// NewLayered builds a Configuration from layers given highest priority
+// first: a key present in several layers takes its value from the earliest.
+func NewLayered(layers ...Source) *Configuration {
+	return NewLayeredWithContext(context.Background(), layers...)
+}
+
+func NewLayeredWithContext(ctx context.Context, layers ...Source) *Configuration {
+	config := &Configuration{
+		parameters: make(map[string]string),
+		layers:     append([]Source{}, layers...),
+	}
+	config.ShouldLogUpdates.Store(DefaultShouldLog)
+	config.start(ctx)
+	return config
+}
+
+func (c *Configuration) updateLayers() error {
+	merged := make(map[string]string)
+	for i := len(c.layers) - 1; i >= 0; i-- {
+		parameters, err := c.layers[i].Load()
+		if err != nil {
+			c.logf("Configuration::updateLayers error loading layer %d: %v\n", i, err)
+			return fmt.Errorf("layer %d: %w", i, err)
+		}
+		for key, value := range parameters {
+			merged[key] = value
+		}
+	}
+	if c.lastupdate != 0 && equal(merged, c.merged) {
+		return nil
+	}
+	if err := c.beforeReload("layers"); err != nil {
+		return err
+	}
+	entries := make([]entry, 0, len(merged))
+	for _, key := range sortedKeys(merged) {
+		entries = append(entries, entry{key: key, value: merged[key]})
+	}
+	c.merged, c.lastupdate = merged, c.clock().UnixNano()
+	return c.apply("updateLayers", "layers", entries, true)
+}
+
+func equal(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for key, value := range a {
+		if stored, found := b[key]; !found || stored != value {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,92 @@
+package configuration
+
+import (
+	"flag"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewLayeredPrecedence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.conf")
+	writeFile(t, path, "host=file\nport=file\nuser=file\n")
+	t.Setenv("LAYERTEST_HOST", "env")
+	t.Setenv("LAYERTEST_PORT", "env")
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.String("host", "", "")
+	if err := flags.Parse([]string{"-host=flag"}); err != nil {
+		t.Fatal(err)
+	}
+	defaults := MapSource{"host": "default", "port": "default", "user": "default", "region": "default"}
+	config := NewLayered(FlagSource{FlagSet: flags}, EnvSource("LAYERTEST_"), FileSource(path), defaults)
+	defer config.Close()
+	want := map[string]string{"host": "flag", "port": "env", "user": "file", "region": "default"}
+	for key, value := range want {
+		if got := config.Get(key); got != value {
+			t.Errorf("%s = %q, want %q", key, got, value)
+		}
+	}
+	writeFile(t, path, "host=file\nport=file\nuser=edited\n")
+	config.Reload()
+	if got := config.Get("user"); got != "edited" {
+		t.Errorf("user = %q after the file layer changed, want edited", got)
+	}
+}
+
+func TestFlagSourceRequiresParse(t *testing.T) {
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	if _, err := (FlagSource{FlagSet: flags}).Load(); err == nil {
+		t.Error("FlagSource loaded an unparsed flag set")
+	}
+}
+
+func TestBindFlags(t *testing.T) {
+	config, path := newTestConfiguration(t, "host=file\nport=8080\nworkers=many\n")
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	host := flags.String("host", "flagdefault", "")
+	port := flags.Int("port", 1, "")
+	flags.Int("workers", 1, "")
+	if err := config.BindFlags(flags); err == nil {
+		t.Error("BindFlags accepted an unparsed flag set")
+	}
+	if err := flags.Parse([]string{"-host=cli"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.BindFlags(flags); err == nil {
+		t.Error("BindFlags did not report the malformed workers value")
+	}
+	if got := config.Get("host"); got != "cli" || *host != "cli" {
+		t.Errorf("host = %q, flag %q, want the command line over the file", got, *host)
+	} else if *port != 8080 {
+		t.Errorf("port flag = %d, want the file value 8080", *port)
+	}
+	writeFile(t, path, "host=edited\nport=8080\n")
+	config.Update()
+	if got := config.Get("host"); got != "cli" {
+		t.Errorf("host = %q after a reload, want the command line kept", got)
+	}
+}
+
+func TestLayeredSkipsUnchangedLayers(t *testing.T) {
+	config := NewLayered(MapSource{"host": "default"})
+	defer config.Close()
+	config.SetKeyValue("host", "runtime")
+	reloads := config.Status().Reloads
+	config.Update()
+	if got := config.Status().Reloads; got != reloads {
+		t.Errorf("Reloads = %d after polling unchanged layers, want %d", got, reloads)
+	}
+	if got := config.Get("host"); got != "runtime" {
+		t.Errorf("host = %q, want the runtime override", got)
+	}
+}
+
+func TestFileSourceDecodesByFormat(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.json")
+	writeFile(t, path, `{"db": {"host": "json.internal"}}`)
+	parameters, err := FileSource(path).Load()
+	if err != nil {
+		t.Fatal(err)
+	} else if got := parameters["db.host"]; got != "json.internal" {
+		t.Errorf("db.host = %q from a JSON FileSource, want json.internal", got)
+	}
+}
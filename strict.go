@@ -0,0 +1,27 @@
+package configuration
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// strictKey trims key and rejects it if it contains whitespace or control
+// characters. Keys that legitimately need spaces must be double-quoted, as
+// in "my key"=value, when StrictKeys is enabled.
+func strictKey(key string) (string, error) {
+	key = strings.TrimSpace(key)
+	if strings.HasPrefix(key, `"`) {
+		if unquoted, err := strconv.Unquote(key); err != nil {
+			return "", fmt.Errorf("malformed quoted key %s: %w", escape(key), err)
+		} else {
+			return unquoted, nil
+		}
+	} else if i := strings.IndexFunc(key, func(r rune) bool { return unicode.IsSpace(r) || unicode.IsControl(r) }); i != -1 {
+		return "", fmt.Errorf("key '%s' contains whitespace or control characters", escape(key))
+	} else if len(key) == 0 {
+		return "", ErrEmptyParameter
+	}
+	return key, nil
+}
@@ -0,0 +1,28 @@
+package configuration
+
+import (
+	"testing"
+)
+
+func TestStrictKeys(t *testing.T) {
+	config, _ := newTestConfiguration(t, "my key=value\nname=ok\n")
+	if got := config.Get("my key"); got != "value" {
+		t.Errorf("my key = %q without StrictKeys, want value", got)
+	}
+	strict, path := newTestConfiguration(t, "", WithErrorThreshold(0))
+	strict.StrictKeys.Store(true)
+	writeFile(t, path, "my key=value\n\"quoted key\"=kept\nname=ok\n")
+	strict.Reload()
+	if got := strict.Get("my key"); got != "" {
+		t.Errorf("StrictKeys stored a key containing whitespace: %q", got)
+	}
+	if got := strict.Get("quoted key"); got != "kept" {
+		t.Errorf("quoted key = %q in strict mode, want kept", got)
+	}
+	if got := strict.Get("name"); got != "ok" {
+		t.Errorf("name = %q in strict mode, want ok", got)
+	}
+	if summary := strict.LastParseSummary(); summary.Skipped != 1 || summary.Errors != 0 {
+		t.Errorf("summary = %+v, want 1 skipped and no errors", summary)
+	}
+}
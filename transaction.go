@@ -0,0 +1,68 @@
+package configuration
+
+import (
+	"errors"
+	"sync"
+)
+
+var (
+	ErrTransactionDone = errors.New("transaction already committed or rolled back")
+)
+
+type Transaction struct {
+	config     *Configuration
+	operations []operation
+	done       bool
+	mutex      sync.Mutex
+}
+
+type operation struct {
+	key, value string
+	remove     bool
+}
+
+func (c *Configuration) Begin() *Transaction {
+	return &Transaction{config: c}
+}
+
+func (t *Transaction) Set(key, value string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.operations = append(t.operations, operation{key: key, value: value})
+}
+
+func (t *Transaction) Delete(key string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.operations = append(t.operations, operation{key: key, remove: true})
+}
+
+func (t *Transaction) Commit() error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if t.done {
+		return ErrTransactionDone
+	}
+	t.done = true
+	t.config.mutex.Lock()
+	defer t.config.release()
+	for _, op := range t.operations {
+		if op.remove {
+			delete(t.config.overrides, op.key)
+			t.config.remove("Commit", op.key)
+		} else {
+			delete(t.config.origins, op.key)
+			t.config.override(op.key)
+			t.config.store("Commit", op.key, op.value)
+		}
+	}
+	t.operations = nil
+	return nil
+}
+
+func (t *Transaction) Rollback() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.done = true
+	t.operations = nil
+}
@@ -0,0 +1,50 @@
+package configuration
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTransaction(t *testing.T) {
+	config, _ := newTestConfiguration(t, "host=a\nport=1\nstale=yes\n")
+
+	rolledBack := config.Begin()
+	rolledBack.Set("host", "rolled-back")
+	rolledBack.Delete("port")
+	rolledBack.Rollback()
+	if err := rolledBack.Commit(); !errors.Is(err, ErrTransactionDone) {
+		t.Errorf("Commit after Rollback = %v, want %v", err, ErrTransactionDone)
+	}
+	if config.Get("host") != "a" || config.Get("port") != "1" {
+		t.Error("a rolled back transaction changed the configuration")
+	}
+
+	tx := config.Begin()
+	tx.Set("host", "b")
+	tx.Set("port", "2")
+	tx.Delete("stale")
+	if config.Get("host") != "a" {
+		t.Error("a staged change was visible before Commit")
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if config.Get("host") != "b" || config.Get("port") != "2" || config.Get("stale") != "" {
+		t.Errorf("after Commit: host=%q port=%q stale=%q", config.Get("host"), config.Get("port"), config.Get("stale"))
+	}
+}
+
+func TestTransactionDeleteClearsOverride(t *testing.T) {
+	config, path := newTestConfiguration(t, "host=a\n")
+	config.SetKeyValue("host", "runtime")
+	tx := config.Begin()
+	tx.Delete("host")
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, path, "host=b\n")
+	config.Reload()
+	if got := config.Get("host"); got != "b" {
+		t.Errorf("host = %q after a deleted override, want the file's b", got)
+	}
+}
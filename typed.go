@@ -0,0 +1,92 @@
+package configuration
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func (c *Configuration) raw(key string) (string, error) {
+	if value, err := c.lookup(key); err != nil {
+		return "", err
+	} else if value = strings.TrimSpace(value); len(value) == 0 {
+		return "", fmt.Errorf("key '%s': %w", key, ErrEmptyParameter)
+	} else {
+		return value, nil
+	}
+}
+
+func (c *Configuration) GetInt(key string) (int, error) {
+	if value, err := c.raw(key); err != nil {
+		return 0, err
+	} else if n, err := strconv.Atoi(value); err != nil {
+		return 0, fmt.Errorf("key '%s': %w", key, err)
+	} else {
+		return n, nil
+	}
+}
+
+func (c *Configuration) GetInt64(key string) (int64, error) {
+	if value, err := c.raw(key); err != nil {
+		return 0, err
+	} else if n, err := strconv.ParseInt(value, 10, 64); err != nil {
+		return 0, fmt.Errorf("key '%s': %w", key, err)
+	} else {
+		return n, nil
+	}
+}
+
+func (c *Configuration) GetBool(key string) (bool, error) {
+	if value, err := c.raw(key); err != nil {
+		return false, err
+	} else if b, err := strconv.ParseBool(value); err != nil {
+		return false, fmt.Errorf("key '%s': %w", key, err)
+	} else {
+		return b, nil
+	}
+}
+
+func (c *Configuration) GetFloat64(key string) (float64, error) {
+	if value, err := c.raw(key); err != nil {
+		return 0, err
+	} else if f, err := strconv.ParseFloat(value, 64); err != nil {
+		return 0, fmt.Errorf("key '%s': %w", key, err)
+	} else {
+		return f, nil
+	}
+}
+
+func (c *Configuration) GetDuration(key string) (time.Duration, error) {
+	if value, err := c.raw(key); err != nil {
+		return 0, err
+	} else if d, err := time.ParseDuration(value); err != nil {
+		return 0, fmt.Errorf("key '%s': %w", key, err)
+	} else {
+		return d, nil
+	}
+}
+
+func (c *Configuration) GetDefault(key, def string) string {
+	return GetOr(c, key, def, func(s string) (string, error) { return s, nil })
+}
+
+func (c *Configuration) GetIntDefault(key string, def int) int {
+	return GetOr(c, key, def, func(s string) (int, error) { return strconv.Atoi(strings.TrimSpace(s)) })
+}
+
+func (c *Configuration) GetInt64Default(key string, def int64) int64 {
+	return GetOr(c, key, def, func(s string) (int64, error) { return strconv.ParseInt(strings.TrimSpace(s), 10, 64) })
+}
+
+func (c *Configuration) GetBoolDefault(key string, def bool) bool {
+	return GetOr(c, key, def, func(s string) (bool, error) { return strconv.ParseBool(strings.TrimSpace(s)) })
+}
+
+func (c *Configuration) GetFloat64Default(key string, def float64) float64 {
+	return GetOr(c, key, def, func(s string) (float64, error) { return strconv.ParseFloat(strings.TrimSpace(s), 64) })
+}
+
+func (c *Configuration) GetDurationDefault(key string, def time.Duration) time.Duration {
+	return GetOr(c, key, def, func(s string) (time.Duration, error) { return time.ParseDuration(strings.TrimSpace(s)) })
+}
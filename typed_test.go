@@ -0,0 +1,71 @@
+package configuration
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestTypedAccessors(t *testing.T) {
+	config, _ := newTestConfiguration(t, "int= 42 \nbig=9000000000\nbool=true\nfloat=2.5\nduration=1m30s\nempty=\nbad=x\n")
+	if got, err := config.GetInt("int"); err != nil || got != 42 {
+		t.Errorf("GetInt = %d, %v, want 42", got, err)
+	}
+	if got, err := config.GetInt64("big"); err != nil || got != 9000000000 {
+		t.Errorf("GetInt64 = %d, %v, want 9000000000", got, err)
+	}
+	if got, err := config.GetBool("bool"); err != nil || !got {
+		t.Errorf("GetBool = %v, %v, want true", got, err)
+	}
+	if got, err := config.GetFloat64("float"); err != nil || got != 2.5 {
+		t.Errorf("GetFloat64 = %v, %v, want 2.5", got, err)
+	}
+	if got, err := config.GetDuration("duration"); err != nil || got != 90*time.Second {
+		t.Errorf("GetDuration = %v, %v, want 1m30s", got, err)
+	}
+	if _, err := config.GetInt("missing"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("GetInt of a missing key = %v, want %v", err, ErrKeyNotFound)
+	}
+	if _, err := config.GetBool("empty"); !errors.Is(err, ErrEmptyParameter) {
+		t.Errorf("GetBool of an empty value = %v, want %v", err, ErrEmptyParameter)
+	}
+	if _, err := config.GetInt("bad"); !errors.Is(err, strconv.ErrSyntax) {
+		t.Errorf("GetInt of a malformed value = %v, want %v", err, strconv.ErrSyntax)
+	}
+	if _, err := config.GetDuration("bad"); err == nil {
+		t.Error("GetDuration accepted a malformed value")
+	}
+}
+
+func TestGetDefault(t *testing.T) {
+	config, _ := newTestConfiguration(t, "name=app\nport= 8080\ndebug=true\nratio=0.5\nbig=9000000000\ntimeout=2s\nempty=\nbad=x\n")
+	if got := config.GetDefault("name", "other"); got != "app" {
+		t.Errorf("GetDefault = %q, want app", got)
+	} else if got := config.GetDefault("missing", "other"); got != "other" {
+		t.Errorf("GetDefault of a missing key = %q, want other", got)
+	} else if got := config.GetDefault("empty", "other"); got != "" {
+		t.Errorf("GetDefault of an empty value = %q, want the empty value", got)
+	}
+	if got := config.GetIntDefault("port", 1); got != 8080 {
+		t.Errorf("GetIntDefault = %d, want 8080", got)
+	} else if got := config.GetIntDefault("bad", 1); got != 1 {
+		t.Errorf("GetIntDefault of a malformed value = %d, want 1", got)
+	}
+	if got := config.GetInt64Default("big", 1); got != 9000000000 {
+		t.Errorf("GetInt64Default = %d, want 9000000000", got)
+	}
+	if got := config.GetBoolDefault("debug", false); !got {
+		t.Error("GetBoolDefault = false, want true")
+	} else if got := config.GetBoolDefault("missing", true); !got {
+		t.Error("GetBoolDefault of a missing key ignored the default")
+	}
+	if got := config.GetFloat64Default("ratio", 1); got != 0.5 {
+		t.Errorf("GetFloat64Default = %v, want 0.5", got)
+	}
+	if got := config.GetDurationDefault("timeout", time.Second); got != 2*time.Second {
+		t.Errorf("GetDurationDefault = %v, want 2s", got)
+	} else if got := config.GetDurationDefault("bad", time.Second); got != time.Second {
+		t.Errorf("GetDurationDefault of a malformed value = %v, want 1s", got)
+	}
+}
@@ -0,0 +1,215 @@
+package configuration
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNotStructPointer = errors.New("target must be a non-nil pointer to a struct")
+)
+
+// Bind allocates a T populated from c and keeps it current until stop is
+// called, see Configuration.Bind.
+func Bind[T any](c *Configuration) (*T, func(), error) {
+	t := new(T)
+	stop, err := c.Bind(t)
+	if err != nil {
+		return nil, nil, err
+	}
+	return t, stop, nil
+}
+
+// Bind populates the struct pointed to by ptr and keeps it current until
+// stop is called: after every applied change to one of its keys the struct
+// is rebuilt and copied into place while holding a lock. Read its fields
+// inside ReadBound to avoid racing with a refresh.
+func (c *Configuration) Bind(ptr any) (stop func(), err error) {
+	if err := c.unmarshal(ptr); err != nil {
+		return nil, err
+	}
+	target := reflect.ValueOf(ptr).Elem()
+	keys := make(map[string]struct{})
+	boundKeys(target, "", keys)
+	return c.subscribe(func(events []Event) {
+		touched := false
+		for _, event := range events {
+			if _, found := keys[event.Key]; found {
+				touched = true
+				break
+			}
+		}
+		if !touched {
+			return
+		}
+		fresh := reflect.New(target.Type())
+		if err := c.unmarshal(fresh.Interface()); err != nil {
+			c.logf("Configuration::Bind error refreshing %T: %v\n", ptr, err)
+			return
+		}
+		c.bound.Lock()
+		target.Set(fresh.Elem())
+		c.bound.Unlock()
+	}), nil
+}
+
+// boundKeys adds the keys unmarshalStruct reads into target to keys.
+func boundKeys(target reflect.Value, prefix string, keys map[string]struct{}) {
+	for i := 0; i < target.NumField(); i++ {
+		field := target.Type().Field(i)
+		s, found := field.Tag.Lookup("config")
+		if !found || s == "-" || !field.IsExported() {
+			continue
+		}
+		key := prefix + parseTag(s).key
+		if field.Type.Kind() == reflect.Struct && !isScalar(target.Field(i)) {
+			boundKeys(target.Field(i), key+".", keys)
+			continue
+		}
+		keys[key] = struct{}{}
+	}
+}
+
+func (c *Configuration) ReadBound(fn func()) {
+	c.bound.RLock()
+	defer c.bound.RUnlock()
+	fn()
+}
+
+// Unmarshal populates the struct pointed to by v from fields tagged
+// `config:"key,default=value,required"`. Nested structs are read with their
+// key and a dot as a prefix, slices from comma-separated values. A default
+// runs to the end of the tag, so it may itself contain commas.
+func (c *Configuration) Unmarshal(v any) error {
+	return c.unmarshal(v)
+}
+
+func (c *Configuration) unmarshal(v any) error {
+	target := reflect.ValueOf(v)
+	if target.Kind() != reflect.Pointer || target.IsNil() || target.Elem().Kind() != reflect.Struct {
+		return ErrNotStructPointer
+	}
+	return c.unmarshalStruct(target.Elem(), "")
+}
+
+type tag struct {
+	key        string
+	def        string
+	hasDefault bool
+	required   bool
+}
+
+func parseTag(s string) tag {
+	parts := strings.Split(s, ",")
+	t := tag{key: parts[0]}
+	for i, part := range parts[1:] {
+		if part == "required" {
+			t.required = true
+		} else if value, found := strings.CutPrefix(part, "default="); found {
+			t.def, t.hasDefault = strings.Join(append([]string{value}, parts[i+2:]...), ","), true
+			break
+		}
+	}
+	return t
+}
+
+func (c *Configuration) unmarshalStruct(target reflect.Value, prefix string) error {
+	errs := make([]error, 0)
+	for i := 0; i < target.NumField(); i++ {
+		field := target.Type().Field(i)
+		s, found := field.Tag.Lookup("config")
+		if !found || s == "-" || !field.IsExported() {
+			continue
+		}
+		t := parseTag(s)
+		key := prefix + t.key
+		if field.Type.Kind() == reflect.Struct && !isScalar(target.Field(i)) {
+			if err := c.unmarshalStruct(target.Field(i), key+"."); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		value, found := c.get(key)
+		if !found && t.hasDefault {
+			value, found = t.def, true
+		}
+		if !found {
+			if t.required {
+				errs = append(errs, fmt.Errorf("%w: '%s'", ErrKeyNotFound, key))
+			}
+			continue
+		} else if err := setField(target.Field(i), value); err != nil {
+			errs = append(errs, fmt.Errorf("key '%s': %w", key, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func isScalar(field reflect.Value) bool {
+	if field.Type() == reflect.TypeOf(time.Duration(0)) {
+		return true
+	}
+	_, ok := field.Addr().Interface().(encoding.TextUnmarshaler)
+	return ok
+}
+
+func setField(field reflect.Value, value string) error {
+	if unmarshaler, ok := field.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return unmarshaler.UnmarshalText([]byte(strings.TrimSpace(value)))
+	}
+	value = strings.TrimSpace(value)
+	if field.Kind() == reflect.Slice {
+		elements := splitList(value, ",")
+		slice := reflect.MakeSlice(field.Type(), len(elements), len(elements))
+		for i, element := range elements {
+			if err := setField(slice.Index(i), element); err != nil {
+				return err
+			}
+		}
+		field.Set(slice)
+		return nil
+	} else if field.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package configuration
+
+import (
+	"errors"
+	"net/netip"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+type boundSettings struct {
+	Workers int           `config:"workers"`
+	Timeout time.Duration `config:"timeout"`
+	Debug   bool          `config:"debug"`
+}
+
+func TestBindRefreshesOnReload(t *testing.T) {
+	config, path := newTestConfiguration(t, "workers=2\ntimeout=1s\n")
+	settings, stop, err := Bind[boundSettings](config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.ReadBound(func() {
+		if settings.Workers != 2 || settings.Timeout != time.Second || settings.Debug {
+			t.Errorf("after Bind: %+v", *settings)
+		}
+	})
+	writeFile(t, path, "workers=4\ntimeout=2s\ndebug=true\n")
+	config.Reload()
+	config.ReadBound(func() {
+		if settings.Workers != 4 || settings.Timeout != 2*time.Second || !settings.Debug {
+			t.Errorf("after reload: %+v", *settings)
+		}
+	})
+	stop()
+	writeFile(t, path, "workers=8\ntimeout=2s\ndebug=true\n")
+	config.Reload()
+	config.ReadBound(func() {
+		if settings.Workers != 4 {
+			t.Errorf("Workers = %d after stop, want 4", settings.Workers)
+		}
+	})
+}
+
+func TestBindRejectsNonStruct(t *testing.T) {
+	config, _ := newTestConfiguration(t, "")
+	if _, _, err := Bind[int](config); !errors.Is(err, ErrNotStructPointer) {
+		t.Errorf("Bind[int] = %v, want %v", err, ErrNotStructPointer)
+	}
+}
+
+type unmarshalSettings struct {
+	Name    string        `config:"name,required"`
+	Tags    []string      `config:"tags"`
+	Ports   []int         `config:"ports,default=80,443"`
+	Timeout time.Duration `config:"timeout,default=5s"`
+	Addr    netip.Addr    `config:"addr"`
+	DB      struct {
+		Host string `config:"host,default=localhost"`
+		Port int    `config:"port"`
+	} `config:"db"`
+	Ignored string `config:"-"`
+	private string `config:"name"`
+}
+
+func TestUnmarshal(t *testing.T) {
+	config, _ := newTestConfiguration(t, "name=app\ntags=a, b,c\naddr=10.0.0.1\ndb.port=05432\n")
+	var settings unmarshalSettings
+	if err := config.Unmarshal(&settings); err != nil {
+		t.Fatal(err)
+	}
+	if settings.Name != "app" || !slices.Equal(settings.Tags, []string{"a", "b", "c"}) || settings.Addr != netip.MustParseAddr("10.0.0.1") {
+		t.Errorf("Unmarshal = %+v", settings)
+	}
+	if !slices.Equal(settings.Ports, []int{80, 443}) || settings.Timeout != 5*time.Second {
+		t.Errorf("defaults = %v, %v, want [80 443] and 5s", settings.Ports, settings.Timeout)
+	}
+	if settings.DB.Host != "localhost" || settings.DB.Port != 5432 {
+		t.Errorf("nested = %+v, want localhost:5432 with the leading zero read as decimal", settings.DB)
+	}
+	if settings.Ignored != "" || settings.private != "" {
+		t.Errorf("Unmarshal set an ignored or unexported field: %+v", settings)
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	config, _ := newTestConfiguration(t, "ports=80,http\n")
+	var settings unmarshalSettings
+	err := config.Unmarshal(&settings)
+	if !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("Unmarshal without a required key = %v, want %v", err, ErrKeyNotFound)
+	} else if !strings.Contains(err.Error(), "'ports'") {
+		t.Errorf("Unmarshal = %v, want the malformed ports reported too", err)
+	}
+	if err := config.Unmarshal(settings); !errors.Is(err, ErrNotStructPointer) {
+		t.Errorf("Unmarshal of a non-pointer = %v, want %v", err, ErrNotStructPointer)
+	}
+}
+
+func TestConfigurationBind(t *testing.T) {
+	config, path := newTestConfiguration(t, "name=app\ndb.port=5432\n")
+	var settings unmarshalSettings
+	stop, err := config.Bind(&settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, path, "name=renamed\ndb.host=db\ndb.port=6432\n")
+	config.Reload()
+	config.ReadBound(func() {
+		if settings.Name != "renamed" || settings.DB.Host != "db" || settings.DB.Port != 6432 {
+			t.Errorf("after reload: %+v", settings)
+		}
+	})
+	writeFile(t, path, "name=again\ndb.port=abc\n")
+	config.Reload()
+	config.ReadBound(func() {
+		if settings.Name != "renamed" || settings.DB.Port != 6432 {
+			t.Errorf("a failed refresh replaced the bound struct: %+v", settings)
+		}
+		settings.Ignored = "marker"
+	})
+	config.SetKeyValue("unbound", "1")
+	config.ReadBound(func() {
+		if settings.Ignored != "marker" {
+			t.Error("a change to an unbound key refreshed the struct")
+		}
+	})
+	stop()
+	writeFile(t, path, "name=stopped\ndb.port=6432\n")
+	config.Reload()
+	config.ReadBound(func() {
+		if settings.Name != "renamed" {
+			t.Errorf("name = %q after stop, want the struct left alone", settings.Name)
+		}
+	})
+	if _, err := config.Bind(settings); !errors.Is(err, ErrNotStructPointer) {
+		t.Errorf("Bind of a non-pointer = %v, want %v", err, ErrNotStructPointer)
+	}
+}
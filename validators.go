@@ -0,0 +1,145 @@
+package configuration
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+type Validator func(parameters map[string]string) error
+
+var (
+	ErrOutOfRange    = errors.New("value out of range")
+	ErrTooManyErrors = errors.New("too many parse errors")
+)
+
+func (c *Configuration) AddValidator(validator Validator) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.validators = append(c.validators, validator)
+}
+
+func (c *Configuration) RequireIntRange(key string, min, max int) {
+	c.AddValidator(func(parameters map[string]string) error {
+		if value, found := parameters[key]; !found {
+			return nil
+		} else if n, err := strconv.Atoi(value); err != nil {
+			return fmt.Errorf("key '%s': %w", key, err)
+		} else if n < min || n > max {
+			return fmt.Errorf("key '%s': %w: %d not in [%d, %d]", key, ErrOutOfRange, n, min, max)
+		} else {
+			return nil
+		}
+	})
+}
+
+// SetErrorThreshold sets how many unparseable lines a reload tolerates.
+// A file with more errors is rejected as a whole and the previous
+// configuration kept, so a half-written file is never partially applied.
+// A negative threshold applies whatever lines did parse.
+//
+// Until a threshold is set, reloads skip and log bad lines as they always
+// have, which means a file with errors, including one caught mid-write, is
+// partially applied. Existing callers keep that behavior; set a threshold,
+// typically zero, to make every reload all-or-nothing. SetFilename and
+// SwapFile refuse a file with any error either way unless a threshold says
+// otherwise.
+func (c *Configuration) SetErrorThreshold(n int) {
+	c.errorThreshold.Store(int64(n))
+	c.thresholdSet.Store(true)
+}
+
+// tolerate rejects errs if they exceed the threshold, or def when no
+// threshold has been set.
+func (c *Configuration) tolerate(caller, source string, errs []error, def int64) error {
+	threshold := def
+	if c.thresholdSet.Load() {
+		threshold = c.errorThreshold.Load()
+	}
+	if threshold < 0 || int64(len(errs)) <= threshold {
+		return nil
+	}
+	err := fmt.Errorf("%w: %d in %s: %w", ErrTooManyErrors, len(errs), source, errors.Join(errs...))
+	c.logf("Configuration::%s rejecting %s: %d parse errors\n", caller, source, len(errs))
+	return err
+}
+
+func (c *Configuration) validate(parameters map[string]string) error {
+	errs := make([]error, 0)
+	for _, validator := range c.validators {
+		if err := validator(parameters); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (c *Configuration) OnReloadError(fn func(error)) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.reloadErrorHooks = append(c.reloadErrorHooks, fn)
+}
+
+// RevalidateEvery re-runs the registered validators against the live
+// parameters on the given interval, even when the file is unchanged. A
+// non-positive interval disables revalidation.
+func (c *Configuration) RevalidateEvery(every time.Duration) {
+	c.revalidateEvery.Store(int64(every))
+}
+
+func (c *Configuration) Revalidate() error {
+	c.mutex.RLock()
+	filename, err := c.filename, c.validate(c.parameters)
+	c.mutex.RUnlock()
+	if err == nil {
+		c.invalid.Store(false)
+		return nil
+	} else if c.invalid.CompareAndSwap(false, true) {
+		c.logf("Configuration::Revalidate %s no longer valid: %v\n", filename, err)
+		c.reloadError(err)
+	}
+	return err
+}
+
+func (c *Configuration) reloadError(err error) {
+	if err == nil {
+		return
+	}
+	c.mutex.Lock()
+	c.lastError, c.lastErrorTime = err, c.clock()
+	c.failures++
+	hooks := append([]func(error){}, c.reloadErrorHooks...)
+	c.mutex.Unlock()
+	for _, hook := range hooks {
+		hook(err)
+	}
+}
+
+// Status reports the health of a Configuration for health checks and
+// metrics.
+type Status struct {
+	LastSuccess   time.Time
+	LastError     error
+	LastErrorTime time.Time
+	Reloads       int64
+	Failures      int64
+}
+
+// Healthy reports whether the most recent load succeeded.
+func (s Status) Healthy() bool {
+	return s.LastError == nil || s.LastSuccess.After(s.LastErrorTime)
+}
+
+func (c *Configuration) Status() Status {
+	c = c.origin()
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return Status{
+		LastSuccess:   c.lastloaded,
+		LastError:     c.lastError,
+		LastErrorTime: c.lastErrorTime,
+		Reloads:       c.reloads,
+		Failures:      c.failures,
+	}
+}
@@ -0,0 +1,118 @@
+package configuration
+
+import (
+	"errors"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRequireIntRangeKeepsLastGood(t *testing.T) {
+	config, path := newTestConfiguration(t, "port=8080\n")
+	config.RequireIntRange("port", 1, 65535)
+	writeFile(t, path, "port=70000\n")
+	config.Update()
+	if got := config.Get("port"); got != "8080" {
+		t.Errorf("port = %q after a rejected reload, want 8080", got)
+	}
+	writeFile(t, path, "port=9090\n")
+	config.Update()
+	if got := config.Get("port"); got != "9090" {
+		t.Errorf("port = %q after a valid reload, want 9090", got)
+	}
+}
+
+func TestRevalidateEveryFiresOnDrift(t *testing.T) {
+	config, _ := newTestConfiguration(t, "secret=abc\n", WithPollInterval(10*time.Millisecond))
+	var failing atomic.Bool
+	errDrifted := errors.New("secret rotated")
+	config.AddValidator(func(map[string]string) error {
+		if failing.Load() {
+			return errDrifted
+		}
+		return nil
+	})
+	fired := make(chan error, 1)
+	config.OnReloadError(func(err error) {
+		select {
+		case fired <- err:
+		default:
+		}
+	})
+	config.RevalidateEvery(10 * time.Millisecond)
+	failing.Store(true)
+	select {
+	case err := <-fired:
+		if !errors.Is(err, errDrifted) {
+			t.Errorf("error hook got %v, want %v", err, errDrifted)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("error hook did not fire on revalidation")
+	}
+}
+
+func TestSetErrorThreshold(t *testing.T) {
+	tests := []struct {
+		threshold int
+		applied   bool
+	}{
+		{0, false},
+		{1, false},
+		{2, true},
+		{-1, true},
+	}
+	for _, test := range tests {
+		config, path := newTestConfiguration(t, "a=1\nb=1\n", WithErrorThreshold(test.threshold))
+		var rejected error
+		config.OnReloadError(func(err error) { rejected = err })
+		writeFile(t, path, "a=2\nbroken\nb=2\nalso broken\n")
+		config.Reload()
+		if applied := config.Get("a") == "2" && config.Get("b") == "2"; applied != test.applied {
+			t.Errorf("threshold %d: applied = %v, want %v", test.threshold, applied, test.applied)
+		} else if !applied && (config.Get("a") != "1" || config.Get("b") != "1") {
+			t.Errorf("threshold %d: a rejected file was partially applied", test.threshold)
+		}
+		if reported := errors.Is(rejected, ErrTooManyErrors); reported == test.applied {
+			t.Errorf("threshold %d: reported %v, want ErrTooManyErrors only when rejected", test.threshold, reported)
+		}
+	}
+}
+
+func TestStatus(t *testing.T) {
+	config, path := newTestConfiguration(t, "key=1\n", WithErrorThreshold(0))
+	if status := config.Status(); !status.Healthy() || status.Reloads != 1 || status.Failures != 0 || status.LastSuccess.IsZero() {
+		t.Errorf("Status after the first load = %+v, want one healthy reload", status)
+	}
+	failed := time.Now().Add(time.Hour)
+	config.setClock(func() time.Time { return failed })
+	writeFile(t, path, "key=2\nbroken\n")
+	config.Reload()
+	status := config.Status()
+	if status.Healthy() || status.Failures != 1 || !errors.Is(status.LastError, ErrTooManyErrors) || !status.LastErrorTime.Equal(failed) {
+		t.Errorf("Status after a rejected reload = %+v, want an unhealthy failure at %v", status, failed)
+	}
+	recovered := failed.Add(time.Minute)
+	config.setClock(func() time.Time { return recovered })
+	writeFile(t, path, "key=3\n")
+	config.Reload()
+	if status := config.Status(); !status.Healthy() || status.Reloads != 2 || status.Failures != 1 || !status.LastSuccess.Equal(recovered) {
+		t.Errorf("Status after recovering = %+v, want healthy with two reloads and one failure", status)
+	}
+}
+
+func TestErrorThresholdUnset(t *testing.T) {
+	config, path := newTestConfiguration(t, "a=1\n")
+	writeFile(t, path, "a=2\nbroken\n")
+	config.Reload()
+	if got := config.Get("a"); got != "2" {
+		t.Errorf("a = %q, want 2: reloads skip bad lines until a threshold is set", got)
+	}
+	swapped := filepath.Join(filepath.Dir(path), "swapped.conf")
+	writeFile(t, swapped, "a=3\nbroken\n")
+	if err := config.SwapFile(swapped); !errors.Is(err, ErrTooManyErrors) {
+		t.Errorf("SwapFile to a file with errors = %v, want ErrTooManyErrors", err)
+	} else if got := config.Get("a"); got != "2" {
+		t.Errorf("a = %q after a refused SwapFile, want 2", got)
+	}
+}
@@ -0,0 +1,75 @@
+package configuration
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// Watch returns a channel that receives the new value of key each time it
+// is added or changed, by a reload or at runtime. A receiver that falls
+// behind only sees the latest value. The channel is closed when ctx ends.
+func (c *Configuration) Watch(ctx context.Context, key string) <-chan string {
+	values := make(chan string, 1)
+	var (
+		mutex  sync.Mutex
+		closed bool
+	)
+	unsubscribe := c.subscribe(func(events []Event) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		for _, event := range events {
+			if closed || event.Key != key || event.Kind == Removed {
+				continue
+			}
+			select {
+			case <-values:
+			default:
+			}
+			values <- event.New
+		}
+	})
+	go func() {
+		<-ctx.Done()
+		unsubscribe()
+		mutex.Lock()
+		defer mutex.Unlock()
+		closed = true
+		close(values)
+	}()
+	return values
+}
+
+// OnChange calls fn with the old and new value each time key is added,
+// changed or removed. An added key has an empty old value and a removed
+// key an empty new one.
+func (c *Configuration) OnChange(key string, fn func(old, new string)) (unsubscribe func()) {
+	return c.Subscribe(func(event Event) {
+		if event.Key == key {
+			fn(event.Old, event.New)
+		}
+	})
+}
+
+func (c *Configuration) OnAnyChange(fn func(key, old, new string)) (unsubscribe func()) {
+	return c.Subscribe(func(event Event) {
+		fn(event.Key, event.Old, event.New)
+	})
+}
+
+// SubscribePrefix calls fn once per reload or runtime change with the
+// events for keys starting with prefix, such as "kafka.", so that a
+// subsystem owning a prefix is notified once rather than per key.
+func (c *Configuration) SubscribePrefix(prefix string, fn func(events []Event)) (unsubscribe func()) {
+	return c.subscribe(func(events []Event) {
+		matched := make([]Event, 0)
+		for _, event := range events {
+			if strings.HasPrefix(event.Key, prefix) {
+				matched = append(matched, event)
+			}
+		}
+		if len(matched) > 0 {
+			fn(matched)
+		}
+	})
+}
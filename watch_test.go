@@ -0,0 +1,83 @@
+package configuration
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWatch(t *testing.T) {
+	config, path := newTestConfiguration(t, "key=1\n")
+	ctx, cancel := context.WithCancel(context.Background())
+	values := config.Watch(ctx, "key")
+	writeFile(t, path, "key=2\n")
+	config.Reload()
+	if got := <-values; got != "2" {
+		t.Errorf("Watch received %q after a reload, want 2", got)
+	}
+	config.SetKeyValue("other", "x")
+	config.SetKeyValue("key", "3")
+	config.SetKeyValue("key", "4")
+	if got := <-values; got != "4" {
+		t.Errorf("Watch received %q for a slow receiver, want the latest value 4", got)
+	}
+	cancel()
+	select {
+	case _, ok := <-values:
+		if ok {
+			t.Error("Watch delivered a value after its context ended")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Watch did not close its channel when the context ended")
+	}
+}
+
+func TestOnChange(t *testing.T) {
+	config, path := newTestConfiguration(t, "key=1\n", WithPruneRemoved())
+	var changes, any []string
+	unsubscribe := config.OnChange("key", func(old, new string) {
+		changes = append(changes, old+"->"+new)
+	})
+	config.OnAnyChange(func(key, old, new string) {
+		any = append(any, key+":"+old+"->"+new)
+	})
+	writeFile(t, path, "key=2\nother=1\n")
+	config.Reload()
+	writeFile(t, path, "other=1\n")
+	config.Reload()
+	if want := []string{"1->2", "2->"}; !slices.Equal(changes, want) {
+		t.Errorf("OnChange saw %v, want %v", changes, want)
+	}
+	if want := []string{"key:1->2", "other:->1", "key:2->"}; !slices.Equal(any, want) {
+		t.Errorf("OnAnyChange saw %v, want %v", any, want)
+	}
+	unsubscribe()
+	config.SetKeyValue("key", "3")
+	if len(changes) != 2 {
+		t.Errorf("OnChange fired after unsubscribing: %v", changes)
+	}
+}
+
+func TestSubscribePrefix(t *testing.T) {
+	config, path := newTestConfiguration(t, "kafka.brokers=a\nkafka.topic=t\ndb.host=h\n")
+	var batches [][]Event
+	config.SubscribePrefix("kafka.", func(events []Event) {
+		batches = append(batches, events)
+	})
+	writeFile(t, path, "kafka.brokers=b\nkafka.topic=u\ndb.host=h2\n")
+	config.Reload()
+	if len(batches) != 1 || len(batches[0]) != 2 {
+		t.Fatalf("SubscribePrefix got %v, want one batch of two kafka events", batches)
+	}
+	for _, event := range batches[0] {
+		if !strings.HasPrefix(event.Key, "kafka.") {
+			t.Errorf("SubscribePrefix delivered %s", event.Key)
+		}
+	}
+	config.SetKeyValue("db.host", "h3")
+	if len(batches) != 1 {
+		t.Errorf("SubscribePrefix fired for a change outside its prefix: %v", batches)
+	}
+}